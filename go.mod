@@ -49,3 +49,5 @@ require (
 )
 
 require go.mongodb.org/mongo-driver v1.17.6
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+)
+
+// Messages pushed to the rider and driver sockets. Each gateway instance gets
+// its own queues so a message reaches whichever instance holds the owner's socket.
+var (
+	riderRoutingKeys = []string{
+		"trip.event.*",
+		"payment.event.*",
+	}
+
+	driverRoutingKeys = []string{
+		contracts.DriverCmdTripRequest,
+		contracts.DriverCmdTripCancelled,
+	}
+)
+
+func startWebSocketBridge(ctx context.Context, rabbitmq *messaging.RabbitMQ, connManager *messaging.ConnectionManager, routingKeys []string) error {
+	return messaging.NewQueueConsumer(rabbitmq, connManager, routingKeys).Start(ctx)
+}
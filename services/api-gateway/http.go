@@ -6,6 +6,12 @@ import (
 	"net/http"
 	grpcclients "ride-sharing/services/api-gateway/grpc_clients"
 	"ride-sharing/shared/contracts"
+	pb "ride-sharing/shared/proto/trip"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func handleTripPreview(w http.ResponseWriter, r *http.Request) {
@@ -95,7 +101,9 @@ func handleTripStart(w http.ResponseWriter, r *http.Request) {
 	tripStart, err := tripService.Client.CreateTrip(r.Context(), reqBody.ToProto())
 
 	if err != nil {
-		log.Printf("Failed to preview a trip: %v", err)
+		log.Printf("Failed to start a trip: %v", err)
+		http.Error(w, status.Convert(err).Message(), httpStatusFromGRPC(err))
+		return
 	}
 
 	response := contracts.APIResponse{
@@ -105,3 +113,173 @@ func handleTripStart(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusCreated, response)
 
 }
+
+func handleTripCancel(w http.ResponseWriter, r *http.Request) {
+	var reqBody cancelTripRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+		http.Error(w, "failed to parse JSON data", http.StatusBadRequest)
+		return
+	}
+
+	defer r.Body.Close()
+
+	if reqBody.TripID == "" || reqBody.Actor == "" || reqBody.ActorID == "" || reqBody.Reason == "" {
+		http.Error(w, "tripId, actor, actorId and reason are required", http.StatusBadRequest)
+		return
+	}
+
+	// the system cancels from inside the trip service only
+	if reqBody.Actor != "rider" && reqBody.Actor != "driver" {
+		http.Error(w, "actor must be rider or driver", http.StatusBadRequest)
+		return
+	}
+
+	tripService, err := grpcclients.NewTripServiceClient()
+	if err != nil {
+		log.Printf("Failed to connect to the trip service: %v", err)
+		http.Error(w, "trip service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	defer tripService.Close()
+
+	cancelled, err := tripService.Client.CancelTrip(r.Context(), reqBody.ToProto())
+	if err != nil {
+		log.Printf("Failed to cancel trip %s: %v", reqBody.TripID, err)
+		http.Error(w, status.Convert(err).Message(), httpStatusFromGRPC(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, contracts.APIResponse{
+		Data: cancelled,
+	})
+}
+
+// handleGetTrip returns a single trip, so a rider can pick up where they were after a reload.
+func handleGetTrip(w http.ResponseWriter, r *http.Request) {
+	tripID := r.PathValue("id")
+	if tripID == "" {
+		http.Error(w, "trip id is required", http.StatusBadRequest)
+		return
+	}
+
+	// only the rider or the driver of the trip may read it
+	userID := r.URL.Query().Get("userId")
+	if userID == "" {
+		http.Error(w, "userId is required", http.StatusBadRequest)
+		return
+	}
+
+	tripService, err := grpcclients.NewTripServiceClient()
+	if err != nil {
+		log.Printf("Failed to connect to the trip service: %v", err)
+		http.Error(w, "trip service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	defer tripService.Close()
+
+	trip, err := tripService.Client.GetTrip(r.Context(), &pb.GetTripRequest{
+		TripID: tripID,
+		UserID: userID,
+	})
+	if err != nil {
+		log.Printf("Failed to get trip %s: %v", tripID, err)
+		http.Error(w, status.Convert(err).Message(), httpStatusFromGRPC(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, contracts.APIResponse{
+		Data: trip,
+	})
+}
+
+// handleListTrips returns the trips of a rider, or of a driver with role=driver, newest first.
+// Filter with status (repeated or comma separated) and page with limit and cursor.
+func handleListTrips(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	userID := query.Get("userId")
+	if userID == "" {
+		http.Error(w, "userId is required", http.StatusBadRequest)
+		return
+	}
+
+	var statuses []string
+	for _, value := range query["status"] {
+		for _, s := range strings.Split(value, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				statuses = append(statuses, s)
+			}
+		}
+	}
+
+	var limit int
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	tripService, err := grpcclients.NewTripServiceClient()
+	if err != nil {
+		log.Printf("Failed to connect to the trip service: %v", err)
+		http.Error(w, "trip service unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	defer tripService.Close()
+
+	req := &pb.ListTripsRequest{
+		UserID:   userID,
+		Statuses: statuses,
+		Limit:    int32(limit),
+		Cursor:   query.Get("cursor"),
+	}
+
+	var trips *pb.ListTripsResponse
+
+	switch role := query.Get("role"); role {
+	case "", "rider":
+		trips, err = tripService.Client.ListRiderTrips(r.Context(), req)
+	case "driver":
+		trips, err = tripService.Client.ListDriverTrips(r.Context(), req)
+	default:
+		http.Error(w, "role must be rider or driver", http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		log.Printf("Failed to list trips of %s: %v", userID, err)
+		http.Error(w, status.Convert(err).Message(), httpStatusFromGRPC(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, contracts.APIResponse{
+		Data: trips,
+	})
+}
+
+// httpStatusFromGRPC maps the status of a failed call to the closest HTTP one.
+func httpStatusFromGRPC(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+	"ride-sharing/shared/types"
+
+	pb "ride-sharing/shared/proto/driver"
+)
+
+// locationData is the data of the driver.cmd.location frames sent by the web client.
+type locationData struct {
+	Location *types.Coordinate `json:"location"`
+}
+
+// locationForwarder sends the location frames of one driver to the driver service,
+// over a single StreamLocation call that is reopened if it breaks.
+type locationForwarder struct {
+	ctx      context.Context
+	client   pb.DriverServiceClient
+	driverID string
+	stream   pb.DriverService_StreamLocationClient
+}
+
+func newLocationForwarder(ctx context.Context, client pb.DriverServiceClient, driverID string) *locationForwarder {
+	return &locationForwarder{
+		ctx:      ctx,
+		client:   client,
+		driverID: driverID,
+	}
+}
+
+func (f *locationForwarder) Send(location *types.Coordinate) error {
+	if f.stream == nil {
+		stream, err := f.client.StreamLocation(f.ctx)
+		if err != nil {
+			return err
+		}
+		f.stream = stream
+	}
+
+	err := f.stream.Send(&pb.LocationUpdate{
+		DriverID: f.driverID,
+		Location: &pb.Location{
+			Latitude:  location.Latitude,
+			Longitude: location.Longitude,
+		},
+	})
+	if err != nil {
+		// the real error comes with the response, the next update opens a new stream
+		_, recvErr := f.stream.CloseAndRecv()
+		f.stream = nil
+
+		if recvErr != nil && !errors.Is(recvErr, io.EOF) {
+			return recvErr
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (f *locationForwarder) Close() {
+	if f.stream == nil {
+		return
+	}
+
+	if _, err := f.stream.CloseAndRecv(); err != nil {
+		log.Printf("Failed to close the location stream of %s: %v", f.driverID, err)
+	}
+
+	f.stream = nil
+}
+
+// watchNearbyDrivers pushes the drivers around the location to the rider until ctx is done.
+func watchNearbyDrivers(ctx context.Context, client pb.DriverServiceClient, connManager *messaging.ConnectionManager, riderID string, location *types.Coordinate) {
+	stream, err := client.WatchNearbyDrivers(ctx, &pb.WatchNearbyDriversRequest{
+		Location: &pb.Location{
+			Latitude:  location.Latitude,
+			Longitude: location.Longitude,
+		},
+	})
+	if err != nil {
+		log.Printf("Failed to watch nearby drivers for %s: %v", riderID, err)
+		return
+	}
+
+	for {
+		nearby, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() == nil && !errors.Is(err, io.EOF) {
+				log.Printf("Nearby drivers stream of %s ended: %v", riderID, err)
+			}
+			return
+		}
+
+		drivers := nearby.GetDrivers()
+		if drivers == nil {
+			// the client expects an array
+			drivers = []*pb.Driver{}
+		}
+
+		if err := connManager.SendMessage(riderID, contracts.WSMessage{
+			Type: contracts.DriverCmdLocation,
+			Data: drivers,
+		}); err != nil {
+			log.Printf("Failed to send nearby drivers to %s: %v", riderID, err)
+			return
+		}
+	}
+}
@@ -9,6 +9,8 @@ type previewTripRequest struct {
 	UserID      string           `json:"userId"`
 	Pickup      types.Coordinate `json:"pickup"`
 	Destination types.Coordinate `json:"destination"`
+	// SkipRouteCache fetches a fresh route, e.g. after a road closure
+	SkipRouteCache bool `json:"skipRouteCache"`
 }
 
 func (p *previewTripRequest) ToProto() *pb.PreviewTripRequest {
@@ -22,6 +24,7 @@ func (p *previewTripRequest) ToProto() *pb.PreviewTripRequest {
 			Latitude:  p.Destination.Latitude,
 			Longitude: p.Destination.Longitude,
 		},
+		SkipRouteCache: p.SkipRouteCache,
 	}
 }
 
@@ -36,3 +39,26 @@ func (s *startTripRequest) ToProto() *pb.CreateTripRequest {
 		RideFareId: s.RideFareID,
 	}
 }
+
+type cancelTripRequest struct {
+	TripID  string `json:"tripId"`
+	Actor   string `json:"actor"`
+	ActorID string `json:"actorId"`
+	Reason  string `json:"reason"`
+	Note    string `json:"note"`
+}
+
+func (c *cancelTripRequest) ToProto() *pb.CancelTripRequest {
+	return &pb.CancelTripRequest{
+		TripID:  c.TripID,
+		Actor:   c.Actor,
+		ActorID: c.ActorID,
+		Reason:  c.Reason,
+		Note:    c.Note,
+	}
+}
+
+// driverStatusData is the data of the driver.cmd.status frames, available, on_break or offline.
+type driverStatusData struct {
+	Status string `json:"status"`
+}
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
 	"ride-sharing/shared/proto/driver"
-
-	grpcclients "ride-sharing/services/api-gateway/grpc_clients"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var upgrader = websocket.Upgrader{
@@ -17,7 +22,7 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-func handleRiderWebSocket(w http.ResponseWriter, r *http.Request) {
+func handleRiderWebSocket(w http.ResponseWriter, r *http.Request, connManager *messaging.ConnectionManager, driverService driver.DriverServiceClient) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 
 	if err != nil {
@@ -25,6 +30,8 @@ func handleRiderWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	defer conn.Close()
+
 	userID := r.URL.Query().Get("userID") // this what frontend will send
 
 	if userID == "" {
@@ -32,6 +39,13 @@ func handleRiderWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	connManager.Add(userID, conn)
+	defer connManager.Remove(userID, conn)
+
+	// the rider sends their location when connecting, from then on we push the drivers around it
+	stopWatching := func() {}
+	defer func() { stopWatching() }()
+
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
@@ -39,15 +53,28 @@ func handleRiderWebSocket(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 
-		log.Printf("Received message: %s", message)
+		var riderMsg contracts.WSDriverMessage
+		if err := json.Unmarshal(message, &riderMsg); err != nil || riderMsg.Type != contracts.DriverCmdLocation {
+			log.Printf("Received message: %s", message)
+			continue
+		}
 
-	}
+		var data locationData
+		if err := json.Unmarshal(riderMsg.Data, &data); err != nil || data.Location == nil {
+			log.Printf("Invalid location from rider %s: %s", userID, message)
+			continue
+		}
 
-	defer conn.Close()
+		stopWatching()
+
+		watchCtx, cancel := context.WithCancel(r.Context())
+		stopWatching = cancel
 
+		go watchNearbyDrivers(watchCtx, driverService, connManager, userID, data.Location)
+	}
 }
 
-func handleDriverWebSocket(w http.ResponseWriter, r *http.Request) {
+func handleDriverWebSocket(w http.ResponseWriter, r *http.Request, connManager *messaging.ConnectionManager, rabbitmq *messaging.RabbitMQ, driverService driver.DriverServiceClient) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 
 	if err != nil {
@@ -79,23 +106,16 @@ func handleDriverWebSocket(w http.ResponseWriter, r *http.Request) {
 	// 	PackageSlug    string `json:"packageSlug"`
 	// }
 	ctx := r.Context()
-	driverService, err := grpcclients.NewDriverServiceClient()
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	defer driverService.Close()
 	defer func() {
-		driverService.Client.UnregisterDriver(ctx, &driver.RegisterDriverRequest{
+		driverService.UnregisterDriver(ctx, &driver.RegisterDriverRequest{
 			DriverID:    userID,
 			PackageSlug: packageSlug,
 		})
-		driverService.Close()
 		log.Println("Driver unregistered: ", userID)
 
 	}()
 
-	driverData, err := driverService.Client.RegisterDriver(ctx, &driver.RegisterDriverRequest{
+	driverData, err := driverService.RegisterDriver(ctx, &driver.RegisterDriverRequest{
 		DriverID:    userID,
 		PackageSlug: packageSlug,
 	})
@@ -105,16 +125,27 @@ func handleDriverWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	connManager.Add(userID, conn)
+	defer connManager.Remove(userID, conn)
+
 	msg := contracts.WSMessage{
-		Type: "driver.cmd.register",
+		Type: contracts.DriverCmdRegister,
 		Data: driverData.Driver,
 	}
 
-	if err := conn.WriteJSON(msg); err != nil {
+	if err := connManager.SendMessage(userID, msg); err != nil {
 		log.Printf("Error sending message: %v", err)
 		return
 	}
 
+	locations := newLocationForwarder(ctx, driverService, userID)
+	defer locations.Close()
+
+	heartbeatCtx, stopHeartbeats := context.WithCancel(ctx)
+	defer stopHeartbeats()
+
+	go sendHeartbeats(heartbeatCtx, driverService, userID, driverHeartbeatInterval)
+
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
@@ -122,8 +153,118 @@ func handleDriverWebSocket(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 
-		log.Printf("Received message: %s", message)
+		if apiErr := handleDriverMessage(ctx, rabbitmq, driverService, locations, userID, message); apiErr != nil {
+			log.Printf("Rejected driver message from %s: %s", userID, apiErr.Message)
+
+			if err := connManager.SendMessage(userID, contracts.WSMessage{
+				Type: contracts.WSMessageTypeError,
+				Data: apiErr,
+			}); err != nil {
+				log.Printf("Error sending message: %v", err)
+			}
+		}
+	}
+}
+
+// handleDriverMessage forwards a driver's trip accept/decline into RabbitMQ, and their location
+// and status to the driver service. The returned error is meant to be sent back to the driver.
+func handleDriverMessage(ctx context.Context, rabbitmq *messaging.RabbitMQ, drivers driver.DriverServiceClient, locations *locationForwarder, driverID string, message []byte) *contracts.APIError {
+	var driverMsg contracts.WSDriverMessage
+	if err := json.Unmarshal(message, &driverMsg); err != nil {
+		return &contracts.APIError{Code: "invalid_message", Message: "message must be a JSON object with a type and data"}
+	}
+
+	switch driverMsg.Type {
+	case contracts.DriverCmdTripAccept, contracts.DriverCmdTripDecline:
+	case contracts.DriverCmdLocation:
+		var data locationData
+		if err := json.Unmarshal(driverMsg.Data, &data); err != nil || data.Location == nil {
+			return &contracts.APIError{Code: "invalid_payload", Message: "location is required"}
+		}
+
+		if err := locations.Send(data.Location); err != nil {
+			log.Printf("Failed to forward the location of %s: %v", driverID, err)
+			return &contracts.APIError{Code: "internal", Message: "failed to update location"}
+		}
+
+		return nil
+	case contracts.DriverCmdStatus:
+		var data driverStatusData
+		if err := json.Unmarshal(driverMsg.Data, &data); err != nil || data.Status == "" {
+			return &contracts.APIError{Code: "invalid_payload", Message: "status is required"}
+		}
+
+		if _, err := drivers.SetDriverStatus(ctx, &driver.SetDriverStatusRequest{
+			DriverID: driverID,
+			Status:   data.Status,
+		}); err != nil {
+			log.Printf("Failed to set the status of %s: %v", driverID, err)
+
+			switch status.Code(err) {
+			case codes.InvalidArgument, codes.FailedPrecondition:
+				return &contracts.APIError{Code: "invalid_status", Message: status.Convert(err).Message()}
+			}
+
+			return &contracts.APIError{Code: "internal", Message: "failed to update status"}
+		}
+
+		return nil
+	default:
+		return &contracts.APIError{Code: "unknown_type", Message: fmt.Sprintf("unknown message type %q", driverMsg.Type)}
+	}
+
+	var payload messaging.DriverTripResponseData
+	if err := json.Unmarshal(driverMsg.Data, &payload); err != nil {
+		return &contracts.APIError{Code: "invalid_payload", Message: "failed to parse message data"}
+	}
+
+	if apiErr := validateDriverTripResponse(driverID, &payload); apiErr != nil {
+		return apiErr
+	}
+
+	if err := messaging.PublishEvent(ctx, rabbitmq, driverMsg.Type, driverID, payload); err != nil {
+		log.Printf("Failed to publish %s: %v", driverMsg.Type, err)
+		return &contracts.APIError{Code: "internal", Message: "failed to forward message"}
+	}
+
+	return nil
+}
+
+// sendHeartbeats keeps the driver online in the driver service while their socket is open.
+// If this gateway dies the heartbeats stop and the driver service takes them offline.
+func sendHeartbeats(ctx context.Context, client driver.DriverServiceClient, driverID string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := client.Heartbeat(ctx, &driver.HeartbeatRequest{DriverID: driverID}); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to send heartbeat for %s: %v", driverID, err)
+		}
+	}
+}
+
+func validateDriverTripResponse(driverID string, payload *messaging.DriverTripResponseData) *contracts.APIError {
+	if payload.TripID == "" {
+		return &contracts.APIError{Code: "invalid_payload", Message: "tripID is required"}
+	}
+
+	if payload.RiderID == "" {
+		return &contracts.APIError{Code: "invalid_payload", Message: "riderID is required"}
+	}
+
+	if payload.Driver == nil || payload.Driver.Id == "" {
+		return &contracts.APIError{Code: "invalid_payload", Message: "driver is required"}
+	}
 
+	if payload.Driver.Id != driverID {
+		return &contracts.APIError{Code: "invalid_payload", Message: "driver does not match the connected driver"}
 	}
 
+	return nil
 }
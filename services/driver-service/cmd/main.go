@@ -11,10 +11,14 @@ import (
 	"ride-sharing/services/driver-service/internal/service"
 	"ride-sharing/shared/env"
 	"ride-sharing/shared/messaging"
+	pb "ride-sharing/shared/proto/driver"
 
 	"syscall"
+	"time"
 
 	grpcserver "google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 var (
@@ -49,21 +53,69 @@ func main() {
 
 	log.Println("Starting RabbitMQ connection")
 
-	service := service.NewService()
+	svc := service.NewService()
 
 	// starting the grpc server
 	grpcserver := grpcserver.NewServer()
 
-	grpchandler.NewGrpcHandler(grpcserver, service)
+	grpchandler.NewGrpcHandler(grpcserver, svc)
 
-	consumer := events.NewTripConsumer(rabbitmq, service)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcserver, healthServer)
+	reportRabbitMQHealth(rabbitmq, healthServer)
+
+	dispatchCfg := service.DefaultDispatchConfig()
+	dispatchCfg.OfferTimeout = time.Duration(env.GetInt("DISPATCH_OFFER_TIMEOUT_SECONDS", int(dispatchCfg.OfferTimeout.Seconds()))) * time.Second
+	dispatchCfg.Deadline = time.Duration(env.GetInt("DISPATCH_DEADLINE_SECONDS", int(dispatchCfg.Deadline.Seconds()))) * time.Second
+
+	dispatcher := service.NewDispatcher(svc, events.NewDispatchPublisher(rabbitmq), dispatchCfg)
+
+	// more than one worker may handle the events of a trip out of order
+	consumerOpts := messaging.DefaultConsumerOptions()
+	consumerOpts.Prefetch = env.GetInt("RABBITMQ_PREFETCH", consumerOpts.Prefetch)
+	consumerOpts.Workers = env.GetInt("RABBITMQ_CONSUMER_WORKERS", consumerOpts.Workers)
+	consumerOpts.HandlerTimeout = time.Duration(env.GetInt("RABBITMQ_HANDLER_TIMEOUT_SECONDS", int(consumerOpts.HandlerTimeout.Seconds()))) * time.Second
+
+	consumer := events.NewTripConsumer(rabbitmq, dispatcher)
 	go func() {
-		if err := consumer.Listen(); err != nil {
+		if err := consumer.Listen(ctx, consumerOpts); err != nil {
 			log.Fatalf("Failed to listen to the message: %v", err)
 		}
 
 	}()
 
+	reaperCfg := service.DefaultReaperConfig()
+	reaperCfg.TTL = time.Duration(env.GetInt("DRIVER_HEARTBEAT_TTL_SECONDS", int(reaperCfg.TTL.Seconds()))) * time.Second
+	reaperCfg.Interval = time.Duration(env.GetInt("DRIVER_REAPER_INTERVAL_SECONDS", int(reaperCfg.Interval.Seconds()))) * time.Second
+
+	driverPublisher := events.NewDriverEventPublisher(rabbitmq)
+	go svc.RunReaper(ctx, reaperCfg, func(ctx context.Context, driver service.StaleDriver) {
+		if driver.Status == service.DriverStatusOffered {
+			if err := dispatcher.DriverOffline(ctx, driver.DriverID, driver.TripID); err != nil {
+				log.Printf("Failed to redispatch trip %s: %v", driver.TripID, err)
+			}
+		}
+
+		if err := driverPublisher.DriverOffline(ctx, driver); err != nil {
+			log.Printf("Failed to publish driver %s offline: %v", driver.DriverID, err)
+		}
+	})
+
+	if env.GetBool("DRIVER_SIMULATION_ENABLED", false) {
+		simCfg := service.DefaultSimulationConfig()
+		simCfg.Tick = time.Duration(env.GetInt("DRIVER_SIMULATION_TICK_MS", int(simCfg.Tick.Milliseconds()))) * time.Millisecond
+		simCfg.SpeedKmh = float64(env.GetInt("DRIVER_SIMULATION_SPEED_KMH", int(simCfg.SpeedKmh)))
+
+		go svc.Simulate(ctx, simCfg, func(ctx context.Context, driver *pb.Driver) {
+			if err := driverPublisher.DriverMoved(ctx, driver); err != nil {
+				log.Printf("Failed to publish the location of driver %s: %v", driver.Id, err)
+			}
+		})
+	}
+
+	supplyPublisher := events.NewSupplyPublisher(rabbitmq, svc, time.Duration(env.GetInt("SUPPLY_PUBLISH_INTERVAL_SECONDS", 15))*time.Second)
+	go supplyPublisher.Run(ctx)
+
 	log.Printf("Starting grpc server Trip service on port %s", lis.Addr().String())
 	go func() {
 		if err := grpcserver.Serve(lis); err != nil {
@@ -78,3 +130,16 @@ func main() {
 	grpcserver.GracefulStop()
 
 }
+
+// reportRabbitMQHealth marks the service not serving while the RabbitMQ connection is down.
+func reportRabbitMQHealth(rabbitmq *messaging.RabbitMQ, healthServer *health.Server) {
+	rabbitmq.OnStateChange(func(state messaging.ConnectionState) {
+		log.Printf("RabbitMQ %s", state)
+
+		if state == messaging.StateConnected {
+			healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		} else {
+			healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+	})
+}
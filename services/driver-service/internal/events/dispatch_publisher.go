@@ -0,0 +1,51 @@
+package events
+
+import (
+	"context"
+	"log"
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+
+	pbt "ride-sharing/shared/proto/trip"
+)
+
+// dispatchPublisher publishes the dispatcher decisions on the trip exchange.
+type dispatchPublisher struct {
+	rabbitmq *messaging.RabbitMQ
+}
+
+func NewDispatchPublisher(rabbitmq *messaging.RabbitMQ) *dispatchPublisher {
+	return &dispatchPublisher{
+		rabbitmq: rabbitmq,
+	}
+}
+
+// OfferTrip notifies the driver about a potential trip
+func (p *dispatchPublisher) OfferTrip(ctx context.Context, driverID string, trip *pbt.Trip) error {
+	if err := messaging.PublishEvent(ctx, p.rabbitmq, contracts.DriverCmdTripRequest, driverID, messaging.TripEventData{Trip: trip}); err != nil {
+		log.Printf("Failed to publish message to exchange: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// NoDriversFound notifies the rider that no drivers are available
+func (p *dispatchPublisher) NoDriversFound(ctx context.Context, trip *pbt.Trip) error {
+	if err := messaging.PublishEvent(ctx, p.rabbitmq, contracts.TripEventNoDriversFound, trip.UserID, messaging.TripEventData{Trip: trip}); err != nil {
+		log.Printf("Failed to publish message to exchange: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// TripCancelled tells the driver the trip they were offered or assigned was cancelled
+func (p *dispatchPublisher) TripCancelled(ctx context.Context, driverID string, trip *pbt.Trip) error {
+	if err := messaging.PublishEvent(ctx, p.rabbitmq, contracts.DriverCmdTripCancelled, driverID, messaging.TripEventData{Trip: trip}); err != nil {
+		log.Printf("Failed to publish message to exchange: %v", err)
+		return err
+	}
+
+	return nil
+}
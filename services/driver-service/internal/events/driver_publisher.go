@@ -0,0 +1,58 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"ride-sharing/services/driver-service/internal/service"
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+	"time"
+
+	pb "ride-sharing/shared/proto/driver"
+)
+
+// driverEventPublisher publishes what happens to the drivers themselves.
+type driverEventPublisher struct {
+	rabbitmq *messaging.RabbitMQ
+}
+
+func NewDriverEventPublisher(rabbitmq *messaging.RabbitMQ) *driverEventPublisher {
+	return &driverEventPublisher{
+		rabbitmq: rabbitmq,
+	}
+}
+
+// DriverOffline announces a driver the reaper took offline.
+func (p *driverEventPublisher) DriverOffline(ctx context.Context, driver service.StaleDriver) error {
+	err := messaging.PublishEvent(ctx, p.rabbitmq, contracts.DriverEventOffline, driver.DriverID, messaging.DriverOfflineData{
+		DriverID:   driver.DriverID,
+		TripID:     driver.TripID,
+		Status:     string(driver.Status),
+		LastSeenAt: driver.LastSeen,
+	})
+
+	if errors.Is(err, messaging.ErrUnroutable) {
+		// it's an announcement, nobody has to be listening
+		return nil
+	}
+
+	return err
+}
+
+// DriverMoved announces the new location of a driver.
+func (p *driverEventPublisher) DriverMoved(ctx context.Context, driver *pb.Driver) error {
+	err := messaging.PublishEvent(ctx, p.rabbitmq, contracts.DriverEventLocation, driver.Id, messaging.DriverLocationData{
+		DriverID: driver.Id,
+		Location: driver.Location,
+		Geohash:  driver.Geohash,
+		Status:   driver.Status,
+		At:       time.Now(),
+	})
+
+	if errors.Is(err, messaging.ErrUnroutable) {
+		// nobody follows the drivers over RabbitMQ yet
+		return nil
+	}
+
+	return err
+}
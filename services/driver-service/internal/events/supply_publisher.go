@@ -0,0 +1,73 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"ride-sharing/services/driver-service/internal/service"
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+	"time"
+)
+
+// SupplyGeohashPrecision matches the surge cells of the trip service (about 5km x 5km).
+const SupplyGeohashPrecision = 5
+
+// supplyPublisher broadcasts how many drivers are around, the trip service uses it for surge pricing.
+type supplyPublisher struct {
+	rabbitmq *messaging.RabbitMQ
+	service  *service.Service
+	interval time.Duration
+}
+
+func NewSupplyPublisher(rabbitmq *messaging.RabbitMQ, service *service.Service, interval time.Duration) *supplyPublisher {
+	return &supplyPublisher{
+		rabbitmq: rabbitmq,
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Run publishes a snapshot every interval until ctx is done.
+func (p *supplyPublisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.publish(ctx); err != nil {
+			log.Printf("Failed to publish driver supply: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *supplyPublisher) publish(ctx context.Context) error {
+	supply := p.service.SupplyByCell(SupplyGeohashPrecision)
+
+	cells := make([]messaging.CellSupply, len(supply))
+	for i, c := range supply {
+		cells[i] = messaging.CellSupply{
+			Geohash:     c.Geohash,
+			PackageSlug: c.PackageSlug,
+			Drivers:     c.Drivers,
+		}
+	}
+
+	err := messaging.PublishEvent(ctx, p.rabbitmq, contracts.DriverEventSupply, "", messaging.DriverSupplyData{
+		Precision: SupplyGeohashPrecision,
+		Cells:     cells,
+		At:        time.Now(),
+	})
+
+	if errors.Is(err, messaging.ErrUnroutable) {
+		// no trip service listening (or surge is off), the next snapshot will do
+		return nil
+	}
+
+	return err
+}
@@ -2,7 +2,6 @@ package events
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"ride-sharing/services/driver-service/internal/service"
 	"ride-sharing/shared/contracts"
@@ -12,77 +11,75 @@ import (
 )
 
 type tripConsumer struct {
-	rabbitmq *messaging.RabbitMQ
-	service  *service.Service
+	rabbitmq   *messaging.RabbitMQ
+	dispatcher *service.Dispatcher
 }
 
-func NewTripConsumer(rabbitmq *messaging.RabbitMQ, service *service.Service) *tripConsumer {
+func NewTripConsumer(rabbitmq *messaging.RabbitMQ, dispatcher *service.Dispatcher) *tripConsumer {
 	return &tripConsumer{
-		rabbitmq: rabbitmq,
-		service:  service,
+		rabbitmq:   rabbitmq,
+		dispatcher: dispatcher,
 	}
 }
 
-func (c *tripConsumer) Listen() error {
-	return c.rabbitmq.ConsumeMessages(messaging.FindAvailableDriversQueue, func(ctx context.Context, msg amqp091.Delivery) error {
-		var tripEvent contracts.AmqpMessage
-		if err := json.Unmarshal(msg.Body, &tripEvent); err != nil {
-			log.Fatal("Failed to unmarshal message: %v", err)
-			return err
+func (c *tripConsumer) Listen(ctx context.Context, opts messaging.ConsumerOptions) error {
+	return c.rabbitmq.ConsumeMessages(ctx, messaging.FindAvailableDriversQueue, opts, func(ctx context.Context, msg amqp091.Delivery) error {
+		tripEvent, err := messaging.DecodeEnvelope(msg)
+		if err != nil {
+			log.Printf("Failed to unmarshal message: %v", err)
+			return messaging.Poison(err)
+		}
+
+		if msg.RoutingKey == contracts.DriverCmdTripDecline {
+			payload, err := messaging.DecodePayload[messaging.DriverTripResponseData](tripEvent)
+			if err != nil {
+				log.Printf("Failed to unmarshal message: %v", err)
+				return messaging.Poison(err)
+			}
+
+			// the next driver is offered once the trip service confirms with driver_not_interested
+			c.dispatcher.Decline(payload.TripID, tripEvent.OwnerID)
+
+			return nil
 		}
 
-		var payload messaging.TripEventData // type for this message
-		if err := json.Unmarshal(tripEvent.Data, &payload); err != nil {
+		// every other message bound to the queue is a trip event
+		payload, err := messaging.DecodePayload[messaging.TripEventData](tripEvent)
+		if err != nil {
 			log.Printf("Failed to unmarshal message: %v", err)
-			return err
+			return messaging.Poison(err)
+		}
+
+		if payload.Trip == nil {
+			log.Printf("Trip event %s without trip, skipping", msg.RoutingKey)
+			return nil
 		}
-		// log.Printf("driver received message: %v", msg)
 
 		switch msg.RoutingKey {
 		case contracts.TripEventCreated, contracts.TripEventDriverNotInterested:
-			return c.handleFindAndNotifyDrivers(ctx, payload)
-		}
+			return c.dispatcher.Dispatch(ctx, payload.Trip)
 
-		log.Printf("Unknown trip event: %+v:", payload)
+		case contracts.TripEventDriverAssigned:
+			if payload.Trip.Driver != nil {
+				c.dispatcher.Assigned(payload.Trip.Id, payload.Trip.Driver.Id, service.TripPickup(payload.Trip))
+			}
 
-		return nil
-	})
-}
+			return nil
 
-func (c *tripConsumer) handleFindAndNotifyDrivers(ctx context.Context, payload messaging.TripEventData) error {
-	log.Printf("payload handleFindAndNotifyDrivers:->", payload)
+		case contracts.TripEventStarted:
+			c.dispatcher.Started(payload.Trip)
+			return nil
 
-	suitableIDs := c.service.FindAvailableDrivers(payload.Trip.SelectedFare.PackageSlug)
+		case contracts.TripEventCancelled:
+			return c.dispatcher.Cancelled(ctx, payload.Trip)
 
-	log.Printf("Found suitable drivers %v", len(suitableIDs))
-
-	if len(suitableIDs) == 0 {
-		// Notify the driver that no drivers are available
-		if err := c.rabbitmq.PublishMessage(ctx, contracts.TripEventNoDriversFound, contracts.AmqpMessage{
-			OwnerID: payload.Trip.UserID,
-		}); err != nil {
-			log.Printf("Failed to publish message to exchange: %v", err)
-			return err
+		case contracts.TripEventCompleted:
+			c.dispatcher.Completed(payload.Trip)
+			return nil
 		}
 
-		return nil
-	}
-
-	suitableDriverID := suitableIDs[0]
-
-	marshalledEvent, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-
-	// Notify the driver about a potential trip
-	if err := c.rabbitmq.PublishMessage(ctx, contracts.DriverCmdTripRequest, contracts.AmqpMessage{
-		OwnerID: suitableDriverID,
-		Data:    marshalledEvent,
-	}); err != nil {
-		log.Printf("Failed to publish message to exchange: %v", err)
-		return err
-	}
+		log.Printf("Unknown trip event: %s", msg.RoutingKey)
 
-	return nil
+		return nil
+	})
 }
@@ -2,7 +2,11 @@ package grpchandler
 
 import (
 	"context"
+	"errors"
+	"io"
+	"log"
 	"ride-sharing/services/driver-service/internal/service"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -11,6 +15,13 @@ import (
 	pb "ride-sharing/shared/proto/driver"
 )
 
+const (
+	// maxWatchRadiusKm keeps a single watcher from asking for the whole city
+	maxWatchRadiusKm = 50.0
+	// minWatchInterval throttles the snapshots sent to a watcher
+	minWatchInterval = 500 * time.Millisecond
+)
+
 type grpcHandler struct {
 	pb.UnimplementedDriverServiceServer
 
@@ -44,3 +55,119 @@ func (h *grpcHandler) UnregisterDriver(ctx context.Context, req *pb.RegisterDriv
 		},
 	}, nil
 }
+
+func (h *grpcHandler) SetDriverStatus(ctx context.Context, req *pb.SetDriverStatusRequest) (*pb.SetDriverStatusResponse, error) {
+	next, err := service.ParseDriverStatus(req.GetStatus())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+
+	driver, err := h.Service.SetStatus(req.GetDriverID(), next)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDriverNotFound):
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		case errors.Is(err, service.ErrDriverBusy), errors.Is(err, service.ErrInvalidDriverTransition):
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to set driver status: %v", err)
+	}
+
+	return &pb.SetDriverStatusResponse{
+		Driver: driver,
+	}, nil
+}
+
+func (h *grpcHandler) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.HeartbeatResponse, error) {
+	driver, err := h.Service.Heartbeat(req.GetDriverID())
+	if err != nil {
+		if errors.Is(err, service.ErrDriverNotFound) {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+
+		return nil, status.Errorf(codes.Internal, "failed to record heartbeat: %v", err)
+	}
+
+	return &pb.HeartbeatResponse{
+		Driver: driver,
+	}, nil
+}
+
+func (h *grpcHandler) StreamLocation(stream pb.DriverService_StreamLocationServer) error {
+	var driverID string
+	var updates int64
+
+	for {
+		update, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&pb.StreamLocationResponse{Updates: updates})
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if update.GetDriverID() == "" || update.GetLocation() == nil {
+			return status.Errorf(codes.InvalidArgument, "driver id and location are required")
+		}
+
+		if driverID == "" {
+			driverID = update.GetDriverID()
+		} else if driverID != update.GetDriverID() {
+			return status.Errorf(codes.InvalidArgument, "stream belongs to driver %s, got an update for %s", driverID, update.GetDriverID())
+		}
+
+		if _, err := h.Service.UpdateLocation(driverID, update.GetLocation()); err != nil {
+			if errors.Is(err, service.ErrDriverNotFound) {
+				return status.Errorf(codes.NotFound, "%v", err)
+			}
+
+			return status.Errorf(codes.Internal, "failed to update location: %v", err)
+		}
+
+		updates++
+	}
+}
+
+func (h *grpcHandler) WatchNearbyDrivers(req *pb.WatchNearbyDriversRequest, stream pb.DriverService_WatchNearbyDriversServer) error {
+	location := req.GetLocation()
+	if location == nil {
+		return status.Errorf(codes.InvalidArgument, "location is required")
+	}
+
+	radiusKm := req.GetRadiusKm()
+	if radiusKm <= 0 {
+		radiusKm = service.DefaultSearchRadiusKm
+	}
+
+	if radiusKm > maxWatchRadiusKm {
+		radiusKm = maxWatchRadiusKm
+	}
+
+	changes, stop := h.Service.WatchLocations()
+	defer stop()
+
+	ctx := stream.Context()
+
+	for {
+		drivers := h.Service.NearbyDrivers(location, radiusKm, req.GetPackageSlug())
+		if err := stream.Send(&pb.NearbyDrivers{Drivers: drivers}); err != nil {
+			log.Printf("Failed to send nearby drivers: %v", err)
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changes:
+		}
+
+		// let a burst of moves settle into one snapshot
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(minWatchInterval):
+		}
+	}
+}
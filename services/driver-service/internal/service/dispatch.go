@@ -0,0 +1,464 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	pbd "ride-sharing/shared/proto/driver"
+	pbt "ride-sharing/shared/proto/trip"
+)
+
+// DispatchNotifier delivers the dispatcher decisions, usually over RabbitMQ.
+type DispatchNotifier interface {
+	OfferTrip(ctx context.Context, driverID string, trip *pbt.Trip) error
+	NoDriversFound(ctx context.Context, trip *pbt.Trip) error
+	TripCancelled(ctx context.Context, driverID string, trip *pbt.Trip) error
+}
+
+type DispatchConfig struct {
+	// OfferTimeout is how long a driver has to answer before the next one is asked
+	OfferTimeout time.Duration
+	// Deadline is how long we keep looking for a driver in total
+	Deadline       time.Duration
+	SearchRadiusKm float64
+	// RedispatchDelay is how long to wait before offering again after a failed delivery
+	RedispatchDelay time.Duration
+}
+
+func DefaultDispatchConfig() DispatchConfig {
+	return DispatchConfig{
+		OfferTimeout:    15 * time.Second,
+		Deadline:        2 * time.Minute,
+		SearchRadiusKm:  DefaultSearchRadiusKm,
+		RedispatchDelay: defaultRedispatchDelay,
+	}
+}
+
+const (
+	defaultRedispatchDelay = 2 * time.Second
+	// finishedRetention is how long a trip that got a driver, got cancelled or ran out of
+	// drivers is remembered, so late messages don't start dispatching it again
+	finishedRetention = time.Hour
+)
+
+type OfferOutcome string
+
+const (
+	OfferPending  OfferOutcome = "pending"
+	OfferDeclined OfferOutcome = "declined"
+	OfferTimedOut OfferOutcome = "timed_out"
+	OfferAccepted OfferOutcome = "accepted"
+	// OfferWithdrawn means the trip was cancelled while the driver was thinking about it
+	OfferWithdrawn OfferOutcome = "withdrawn"
+)
+
+// DriverOffer is one attempt at handing a trip to a driver.
+type DriverOffer struct {
+	DriverID    string
+	OfferedAt   time.Time
+	Outcome     OfferOutcome
+	RespondedAt time.Time
+}
+
+type tripDispatch struct {
+	trip      *pbt.Trip
+	startedAt time.Time
+	offers    []*DriverOffer
+	excluded  map[string]bool
+	current   *DriverOffer
+	timer     *time.Timer
+}
+
+// Dispatcher offers a trip to one driver at a time, moving on to the next closest
+// driver when the current one declines or does not answer in time.
+type Dispatcher struct {
+	service  *Service
+	notifier DispatchNotifier
+	cfg      DispatchConfig
+
+	trips map[string]*tripDispatch
+	// finished holds the trips that must not be dispatched again
+	finished map[string]bool
+	mu       sync.Mutex
+}
+
+func NewDispatcher(service *Service, notifier DispatchNotifier, cfg DispatchConfig) *Dispatcher {
+	if cfg.RedispatchDelay <= 0 {
+		cfg.RedispatchDelay = defaultRedispatchDelay
+	}
+
+	return &Dispatcher{
+		service:  service,
+		notifier: notifier,
+		cfg:      cfg,
+		trips:    make(map[string]*tripDispatch),
+		finished: make(map[string]bool),
+	}
+}
+
+// Dispatch offers the trip to the next suitable driver, unless an offer is already out.
+// It is called when a trip is created and every time it comes back unassigned.
+func (d *Dispatcher) Dispatch(ctx context.Context, trip *pbt.Trip) error {
+	d.mu.Lock()
+
+	if d.finished[trip.Id] {
+		d.mu.Unlock()
+		log.Printf("Trip %s is no longer dispatched", trip.Id)
+		return nil
+	}
+
+	st, ok := d.trips[trip.Id]
+	if !ok {
+		st = &tripDispatch{
+			trip:      trip,
+			startedAt: time.Now(),
+			excluded:  make(map[string]bool),
+		}
+		d.trips[trip.Id] = st
+	}
+
+	if st.current != nil {
+		d.mu.Unlock()
+		log.Printf("Trip %s already offered to %s", trip.Id, st.current.DriverID)
+		return nil
+	}
+
+	st.trip = trip
+	offer := d.nextOffer(st)
+	d.mu.Unlock()
+
+	return d.send(ctx, trip, offer)
+}
+
+// Decline records that the driver turned the trip down, they will not be asked again.
+func (d *Dispatcher) Decline(tripID, driverID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st, ok := d.trips[tripID]
+	if !ok {
+		return
+	}
+
+	st.excluded[driverID] = true
+	if st.current != nil && st.current.DriverID == driverID {
+		d.closeOffer(st, OfferDeclined)
+	}
+}
+
+// Assigned stops dispatching the trip once a driver got it, the driver is busy until the trip ends.
+func (d *Dispatcher) Assigned(tripID, driverID string, pickup *pbd.Location) {
+	if err := d.service.AssignTrip(driverID, tripID, pickup); err != nil {
+		log.Printf("Failed to send driver %s to the pickup of trip %s: %v", driverID, tripID, err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if st, ok := d.trips[tripID]; ok && st.current != nil {
+		if st.current.DriverID == driverID {
+			d.closeOffer(st, OfferAccepted)
+		} else {
+			// someone answered an older offer first
+			d.closeOffer(st, OfferWithdrawn)
+		}
+	}
+
+	d.finish(tripID)
+}
+
+// Cancelled stops dispatching a cancelled trip and tells the driver holding the
+// offer, or the assigned driver, unless they cancelled it themselves.
+func (d *Dispatcher) Cancelled(ctx context.Context, trip *pbt.Trip) error {
+	d.mu.Lock()
+
+	var notify []string
+	if st, ok := d.trips[trip.Id]; ok {
+		if st.current != nil {
+			notify = append(notify, st.current.DriverID)
+			d.closeOffer(st, OfferWithdrawn)
+		}
+	}
+
+	d.finish(trip.Id)
+	d.mu.Unlock()
+
+	if driverID := trip.GetDriver().GetId(); driverID != "" {
+		d.service.ReleaseDriver(driverID, trip.Id)
+
+		if len(notify) == 0 || notify[0] != driverID {
+			notify = append(notify, driverID)
+		}
+	}
+
+	for _, driverID := range notify {
+		if trip.GetCancellation().GetActorID() == driverID {
+			continue
+		}
+
+		if err := d.notifier.TripCancelled(ctx, driverID, trip); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Started puts the driver on the trip once they picked the rider up.
+func (d *Dispatcher) Started(trip *pbt.Trip) {
+	driverID := trip.GetDriver().GetId()
+	if driverID == "" {
+		return
+	}
+
+	if err := d.service.StartTrip(driverID, trip.Id, TripDropoff(trip)); err != nil {
+		log.Printf("Failed to start trip %s for driver %s: %v", trip.Id, driverID, err)
+	}
+}
+
+// Completed frees the driver of a finished trip for new dispatches.
+func (d *Dispatcher) Completed(trip *pbt.Trip) {
+	if driverID := trip.GetDriver().GetId(); driverID != "" {
+		d.service.ReleaseDriver(driverID, trip.Id)
+	}
+}
+
+// DriverOffline moves on to the next driver when the one holding the offer went away,
+// rather than waiting for the offer to time out.
+func (d *Dispatcher) DriverOffline(ctx context.Context, driverID, tripID string) error {
+	d.mu.Lock()
+
+	st, ok := d.trips[tripID]
+	if !ok || st.current == nil || st.current.DriverID != driverID {
+		d.mu.Unlock()
+		return nil
+	}
+
+	log.Printf("Driver %s went offline holding the offer of trip %s", driverID, tripID)
+
+	d.closeOffer(st, OfferTimedOut)
+
+	trip := st.trip
+	next := d.nextOffer(st)
+	d.mu.Unlock()
+
+	// redelivering the offline event would find nobody holding the offer anymore
+	if err := d.send(ctx, trip, next); err != nil {
+		log.Printf("Failed to dispatch trip %s: %v", tripID, err)
+		d.redispatchLater(trip)
+	}
+
+	return nil
+}
+
+// Offers returns the offer history of a trip still being dispatched.
+func (d *Dispatcher) Offers(tripID string) []DriverOffer {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st, ok := d.trips[tripID]
+	if !ok {
+		return nil
+	}
+
+	offers := make([]DriverOffer, len(st.offers))
+	for i, o := range st.offers {
+		offers[i] = *o
+	}
+
+	return offers
+}
+
+func (d *Dispatcher) expire(tripID string, offer *DriverOffer) {
+	d.mu.Lock()
+
+	st, ok := d.trips[tripID]
+	if !ok || st.current != offer {
+		// answered in the meantime
+		d.mu.Unlock()
+		return
+	}
+
+	log.Printf("Offer of trip %s to %s timed out", tripID, offer.DriverID)
+
+	st.excluded[offer.DriverID] = true
+	d.closeOffer(st, OfferTimedOut)
+
+	trip := st.trip
+	next := d.nextOffer(st)
+	d.mu.Unlock()
+
+	if err := d.send(context.Background(), trip, next); err != nil {
+		log.Printf("Failed to dispatch trip %s: %v", tripID, err)
+		d.redispatchLater(trip)
+	}
+}
+
+// redispatchLater dispatches the trip again after a failed delivery that no message redelivery will retry.
+func (d *Dispatcher) redispatchLater(trip *pbt.Trip) {
+	time.AfterFunc(d.cfg.RedispatchDelay, func() {
+		if err := d.Dispatch(context.Background(), trip); err != nil {
+			log.Printf("Failed to dispatch trip %s: %v", trip.Id, err)
+			d.redispatchLater(trip)
+		}
+	})
+}
+
+// nextOffer picks the closest driver not asked yet and starts the offer timer.
+// It returns nil when nobody is left or the deadline passed, the trip is finished
+// once the rider was told. Must be called with d.mu held.
+func (d *Dispatcher) nextOffer(st *tripDispatch) *DriverOffer {
+	elapsed := time.Since(st.startedAt)
+	if elapsed >= d.cfg.Deadline {
+		log.Printf("Dispatch deadline passed for trip %s", st.trip.Id)
+		return nil
+	}
+
+	candidates := d.service.FindAvailableDrivers(
+		st.trip.GetSelectedFare().GetPackageSlug(),
+		TripPickup(st.trip),
+		d.cfg.SearchRadiusKm,
+	)
+
+	for _, driverID := range candidates {
+		if st.excluded[driverID] {
+			continue
+		}
+
+		// another trip may have got them since the search
+		if err := d.service.OfferTrip(driverID, st.trip.Id); err != nil {
+			log.Printf("Skipping driver %s for trip %s: %v", driverID, st.trip.Id, err)
+			continue
+		}
+
+		offer := &DriverOffer{
+			DriverID:  driverID,
+			OfferedAt: time.Now(),
+			Outcome:   OfferPending,
+		}
+
+		st.offers = append(st.offers, offer)
+		st.excluded[driverID] = true
+		st.current = offer
+
+		// never wait past the overall deadline
+		timeout := min(d.cfg.OfferTimeout, d.cfg.Deadline-elapsed)
+		tripID := st.trip.Id
+		st.timer = time.AfterFunc(timeout, func() {
+			d.expire(tripID, offer)
+		})
+
+		return offer
+	}
+
+	log.Printf("No drivers left for trip %s after %d offers", st.trip.Id, len(st.offers))
+
+	return nil
+}
+
+// closeOffer records the answer, a driver who did not get the trip is available again.
+// Must be called with d.mu held.
+func (d *Dispatcher) closeOffer(st *tripDispatch, outcome OfferOutcome) {
+	if st.timer != nil {
+		st.timer.Stop()
+		st.timer = nil
+	}
+
+	if outcome != OfferAccepted {
+		d.service.ReleaseOffer(st.current.DriverID, st.trip.Id)
+	}
+
+	st.current.Outcome = outcome
+	st.current.RespondedAt = time.Now()
+	st.current = nil
+}
+
+// finish stops dispatching the trip for good. Must be called with d.mu held.
+func (d *Dispatcher) finish(tripID string) {
+	delete(d.trips, tripID)
+	d.finished[tripID] = true
+
+	time.AfterFunc(finishedRetention, func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+
+		delete(d.finished, tripID)
+	})
+}
+
+// send delivers the offer, or tells the rider nobody was found when offer is nil.
+// An offer that could not be delivered is taken back, the driver never saw it.
+func (d *Dispatcher) send(ctx context.Context, trip *pbt.Trip, offer *DriverOffer) error {
+	if offer == nil {
+		if err := d.notifier.NoDriversFound(ctx, trip); err != nil {
+			return err
+		}
+
+		d.mu.Lock()
+		d.finish(trip.Id)
+		d.mu.Unlock()
+
+		return nil
+	}
+
+	if err := d.notifier.OfferTrip(ctx, offer.DriverID, trip); err != nil {
+		d.withdraw(trip.Id, offer)
+		return err
+	}
+
+	return nil
+}
+
+// withdraw takes back an offer that never reached the driver, they can be asked again.
+func (d *Dispatcher) withdraw(tripID string, offer *DriverOffer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st, ok := d.trips[tripID]
+	if !ok || st.current != offer {
+		return
+	}
+
+	d.closeOffer(st, OfferWithdrawn)
+	st.offers = st.offers[:len(st.offers)-1]
+	delete(st.excluded, offer.DriverID)
+}
+
+// TripPickup returns the first point of the trip route.
+// The trip service stores the OSRM [lng, lat] pairs as-is in the Coordinate
+// latitude/longitude fields (the web client swaps them back), so we do the same.
+func TripPickup(trip *pbt.Trip) *pbd.Location {
+	route := trip.GetRoute()
+	if route == nil || len(route.Geometry) == 0 || len(route.Geometry[0].Coordinates) == 0 {
+		return nil
+	}
+
+	first := route.Geometry[0].Coordinates[0]
+
+	return &pbd.Location{
+		Latitude:  first.Longitude,
+		Longitude: first.Latitude,
+	}
+}
+
+// TripDropoff returns the last point of the trip route, swapped back like TripPickup.
+func TripDropoff(trip *pbt.Trip) *pbd.Location {
+	route := trip.GetRoute()
+	if route == nil || len(route.Geometry) == 0 {
+		return nil
+	}
+
+	coords := route.Geometry[len(route.Geometry)-1].Coordinates
+	if len(coords) == 0 {
+		return nil
+	}
+
+	last := coords[len(coords)-1]
+
+	return &pbd.Location{
+		Latitude:  last.Longitude,
+		Longitude: last.Latitude,
+	}
+}
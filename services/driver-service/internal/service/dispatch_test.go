@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	pbd "ride-sharing/shared/proto/driver"
+	pbt "ride-sharing/shared/proto/trip"
+)
+
+// recordingNotifier records the dispatcher decisions as "offer <driver>", "none" and "cancelled <driver>".
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []string
+	// sent gets every event too, for the ones made by the offer timers
+	sent chan string
+	// failOffers is how many offers fail to be delivered before they go through
+	failOffers int
+}
+
+func newRecordingNotifier() *recordingNotifier {
+	return &recordingNotifier{sent: make(chan string, 100)}
+}
+
+func (n *recordingNotifier) record(event string) error {
+	n.mu.Lock()
+	n.events = append(n.events, event)
+	n.mu.Unlock()
+
+	n.sent <- event
+
+	return nil
+}
+
+func (n *recordingNotifier) OfferTrip(ctx context.Context, driverID string, trip *pbt.Trip) error {
+	n.mu.Lock()
+	fail := n.failOffers > 0
+	if fail {
+		n.failOffers--
+	}
+	n.mu.Unlock()
+
+	if fail {
+		return errors.New("broker unavailable")
+	}
+
+	return n.record("offer " + driverID)
+}
+
+func (n *recordingNotifier) NoDriversFound(ctx context.Context, trip *pbt.Trip) error {
+	return n.record("none")
+}
+
+func (n *recordingNotifier) TripCancelled(ctx context.Context, driverID string, trip *pbt.Trip) error {
+	return n.record("cancelled " + driverID)
+}
+
+func (n *recordingNotifier) recorded() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return append([]string(nil), n.events...)
+}
+
+// waitFor waits for the event, skipping the ones before it.
+func (n *recordingNotifier) waitFor(t *testing.T, event string) {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case got := <-n.sent:
+			if got == event {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no %q, got %v", event, n.recorded())
+		}
+	}
+}
+
+// newDispatchTest registers sedan drivers 0.5km ("near"), 1.5km ("mid") and 3km ("far")
+// from the pickup of the returned trip, and an suv driver right at it.
+func newDispatchTest(t *testing.T, cfg DispatchConfig) (*Service, *Dispatcher, *recordingNotifier, *pbt.Trip) {
+	t.Helper()
+
+	s := NewService()
+
+	drivers := []struct {
+		id, pkg   string
+		latOffset float64
+	}{
+		{id: "near", pkg: "sedan", latOffset: 0.0045},
+		{id: "mid", pkg: "sedan", latOffset: 0.0135},
+		{id: "far", pkg: "sedan", latOffset: -0.027},
+		{id: "suv", pkg: "suv", latOffset: 0.001},
+	}
+
+	for _, d := range drivers {
+		if _, err := s.RegisterDriver(d.id, d.pkg); err != nil {
+			t.Fatalf("RegisterDriver: %v", err)
+		}
+
+		if _, err := s.UpdateLocation(d.id, &pbd.Location{Latitude: centerLat + d.latOffset, Longitude: centerLng}); err != nil {
+			t.Fatalf("UpdateLocation: %v", err)
+		}
+	}
+
+	trip := &pbt.Trip{
+		Id:           "t1",
+		SelectedFare: &pbt.RideFare{PackageSlug: "sedan"},
+		Route: &pbt.Route{Geometry: []*pbt.Geometry{{Coordinates: []*pbt.Coordinate{
+			// [lng, lat] like the trip service stores them
+			{Latitude: centerLng, Longitude: centerLat},
+		}}}},
+	}
+
+	notifier := newRecordingNotifier()
+
+	return s, NewDispatcher(s, notifier, cfg), notifier, trip
+}
+
+func outcomes(offers []DriverOffer) []string {
+	var got []string
+	for _, o := range offers {
+		got = append(got, o.DriverID+":"+string(o.Outcome))
+	}
+
+	return got
+}
+
+func TestDispatcher(t *testing.T) {
+	dispatch := func(ctx context.Context, d *Dispatcher, trip *pbt.Trip) error {
+		return d.Dispatch(ctx, trip)
+	}
+
+	decline := func(driverID string) func(context.Context, *Dispatcher, *pbt.Trip) error {
+		return func(ctx context.Context, d *Dispatcher, trip *pbt.Trip) error {
+			d.Decline(trip.Id, driverID)
+			return d.Dispatch(ctx, trip)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		steps      []func(context.Context, *Dispatcher, *pbt.Trip) error
+		wantEvents []string
+		// wantOffers is the offer history, empty once the trip is no longer dispatched
+		wantOffers []string
+		wantStatus map[string]DriverStatus
+	}{
+		{
+			name:       "closest driver of the package first",
+			steps:      []func(context.Context, *Dispatcher, *pbt.Trip) error{dispatch},
+			wantEvents: []string{"offer near"},
+			wantOffers: []string{"near:pending"},
+			wantStatus: map[string]DriverStatus{"near": DriverStatusOffered, "suv": DriverStatusAvailable},
+		},
+		{
+			name:       "one offer at a time",
+			steps:      []func(context.Context, *Dispatcher, *pbt.Trip) error{dispatch, dispatch},
+			wantEvents: []string{"offer near"},
+			wantOffers: []string{"near:pending"},
+		},
+		{
+			name:       "declined goes to the next closest",
+			steps:      []func(context.Context, *Dispatcher, *pbt.Trip) error{dispatch, decline("near")},
+			wantEvents: []string{"offer near", "offer mid"},
+			wantOffers: []string{"near:declined", "mid:pending"},
+			wantStatus: map[string]DriverStatus{"near": DriverStatusAvailable, "mid": DriverStatusOffered},
+		},
+		{
+			name:       "everyone declined",
+			steps:      []func(context.Context, *Dispatcher, *pbt.Trip) error{dispatch, decline("near"), decline("mid"), decline("far")},
+			wantEvents: []string{"offer near", "offer mid", "offer far", "none"},
+			wantStatus: map[string]DriverStatus{"near": DriverStatusAvailable, "mid": DriverStatusAvailable, "far": DriverStatusAvailable},
+		},
+		{
+			name:       "late decline after nobody was found",
+			steps:      []func(context.Context, *Dispatcher, *pbt.Trip) error{dispatch, decline("near"), decline("mid"), decline("far"), decline("far"), dispatch},
+			wantEvents: []string{"offer near", "offer mid", "offer far", "none"},
+			wantStatus: map[string]DriverStatus{"near": DriverStatusAvailable, "mid": DriverStatusAvailable, "far": DriverStatusAvailable},
+		},
+		{
+			name: "accepted",
+			steps: []func(context.Context, *Dispatcher, *pbt.Trip) error{dispatch, func(ctx context.Context, d *Dispatcher, trip *pbt.Trip) error {
+				d.Assigned(trip.Id, "near", TripPickup(trip))
+				return nil
+			}},
+			wantEvents: []string{"offer near"},
+			wantStatus: map[string]DriverStatus{"near": DriverStatusEnRouteToPickup},
+		},
+		{
+			name: "created event redelivered after the assignment",
+			steps: []func(context.Context, *Dispatcher, *pbt.Trip) error{dispatch, func(ctx context.Context, d *Dispatcher, trip *pbt.Trip) error {
+				d.Assigned(trip.Id, "near", TripPickup(trip))
+				return nil
+			}, dispatch},
+			wantEvents: []string{"offer near"},
+			wantStatus: map[string]DriverStatus{"near": DriverStatusEnRouteToPickup, "mid": DriverStatusAvailable},
+		},
+		{
+			name: "cancelled while offered",
+			steps: []func(context.Context, *Dispatcher, *pbt.Trip) error{dispatch, func(ctx context.Context, d *Dispatcher, trip *pbt.Trip) error {
+				return d.Cancelled(ctx, trip)
+			}},
+			wantEvents: []string{"offer near", "cancelled near"},
+			wantStatus: map[string]DriverStatus{"near": DriverStatusAvailable},
+		},
+		{
+			name: "driver went offline holding the offer",
+			steps: []func(context.Context, *Dispatcher, *pbt.Trip) error{dispatch, func(ctx context.Context, d *Dispatcher, trip *pbt.Trip) error {
+				return d.DriverOffline(ctx, "near", trip.Id)
+			}},
+			wantEvents: []string{"offer near", "offer mid"},
+			wantOffers: []string{"near:timed_out", "mid:pending"},
+		},
+		{
+			name: "busy driver is skipped",
+			steps: []func(context.Context, *Dispatcher, *pbt.Trip) error{func(ctx context.Context, d *Dispatcher, trip *pbt.Trip) error {
+				if err := d.service.AssignTrip("near", "t0", nil); err != nil {
+					return err
+				}
+
+				return d.Dispatch(ctx, trip)
+			}},
+			wantEvents: []string{"offer mid"},
+			wantOffers: []string{"mid:pending"},
+			wantStatus: map[string]DriverStatus{"near": DriverStatusEnRouteToPickup},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, d, notifier, trip := newDispatchTest(t, DispatchConfig{
+				OfferTimeout:   time.Minute,
+				Deadline:       time.Hour,
+				SearchRadiusKm: DefaultSearchRadiusKm,
+			})
+
+			ctx := context.Background()
+			for i, step := range tt.steps {
+				if err := step(ctx, d, trip); err != nil {
+					t.Fatalf("step %d: %v", i, err)
+				}
+			}
+
+			if got := notifier.recorded(); !reflect.DeepEqual(got, tt.wantEvents) {
+				t.Fatalf("events = %v, want %v", got, tt.wantEvents)
+			}
+
+			if got := outcomes(d.Offers(trip.Id)); !reflect.DeepEqual(got, tt.wantOffers) {
+				t.Fatalf("offers = %v, want %v", got, tt.wantOffers)
+			}
+
+			for driverID, want := range tt.wantStatus {
+				if got := s.drivers[driverID].Status; got != want {
+					t.Fatalf("%s is %s, want %s", driverID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDispatcherOfferTimeout(t *testing.T) {
+	s, d, notifier, trip := newDispatchTest(t, DispatchConfig{
+		OfferTimeout:   20 * time.Millisecond,
+		Deadline:       time.Hour,
+		SearchRadiusKm: DefaultSearchRadiusKm,
+	})
+
+	if err := d.Dispatch(context.Background(), trip); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+
+	notifier.waitFor(t, "offer far")
+
+	// the timer of far is still running, the history is only read
+	want := []string{"near:timed_out", "mid:timed_out", "far:pending"}
+	if got := outcomes(d.Offers(trip.Id)); !reflect.DeepEqual(got, want) {
+		t.Fatalf("offers = %v, want %v", got, want)
+	}
+
+	notifier.waitFor(t, "none")
+
+	if got := s.drivers["near"].Status; got != DriverStatusAvailable {
+		t.Fatalf("near is %s after the timeout, want available", got)
+	}
+}
+
+func TestDispatcherDeadline(t *testing.T) {
+	_, d, notifier, trip := newDispatchTest(t, DispatchConfig{
+		OfferTimeout:   time.Minute,
+		Deadline:       30 * time.Millisecond,
+		SearchRadiusKm: DefaultSearchRadiusKm,
+	})
+
+	if err := d.Dispatch(context.Background(), trip); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+
+	// the offer is cut short by the deadline, nobody else is asked
+	notifier.waitFor(t, "none")
+
+	want := []string{"offer near", "none"}
+	if got := notifier.recorded(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+}
+
+func TestDispatcherUndeliveredOffer(t *testing.T) {
+	s, d, notifier, trip := newDispatchTest(t, DispatchConfig{
+		OfferTimeout:   time.Minute,
+		Deadline:       time.Hour,
+		SearchRadiusKm: DefaultSearchRadiusKm,
+	})
+	notifier.failOffers = 1
+
+	if err := d.Dispatch(context.Background(), trip); err == nil {
+		t.Fatalf("Dispatch succeeded, want the delivery error")
+	}
+
+	if got := s.drivers["near"].Status; got != DriverStatusAvailable {
+		t.Fatalf("near is %s after the failed offer, want available", got)
+	}
+
+	if got := d.Offers(trip.Id); len(got) != 0 {
+		t.Fatalf("offers = %v, want the undelivered one taken back", outcomes(got))
+	}
+
+	// the redelivered message asks the same driver again
+	if err := d.Dispatch(context.Background(), trip); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+
+	want := []string{"near:pending"}
+	if got := outcomes(d.Offers(trip.Id)); !reflect.DeepEqual(got, want) {
+		t.Fatalf("offers = %v, want %v", got, want)
+	}
+}
+
+func TestDispatcherUndeliveredOfferAfterTimeout(t *testing.T) {
+	_, d, notifier, trip := newDispatchTest(t, DispatchConfig{
+		OfferTimeout:    20 * time.Millisecond,
+		Deadline:        time.Hour,
+		SearchRadiusKm:  DefaultSearchRadiusKm,
+		RedispatchDelay: 10 * time.Millisecond,
+	})
+
+	if err := d.Dispatch(context.Background(), trip); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+
+	notifier.mu.Lock()
+	notifier.failOffers = 1
+	notifier.mu.Unlock()
+
+	// the offer to mid made by the timer fails, nobody redelivers a message for it
+	notifier.waitFor(t, "offer mid")
+}
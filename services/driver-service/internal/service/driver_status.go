@@ -0,0 +1,109 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DriverStatus is what a registered driver is up to, only available drivers are offered trips.
+type DriverStatus string
+
+const (
+	DriverStatusOffline         DriverStatus = "offline"
+	DriverStatusAvailable       DriverStatus = "available"
+	DriverStatusOffered         DriverStatus = "offered"
+	DriverStatusEnRouteToPickup DriverStatus = "en_route_to_pickup"
+	DriverStatusOnTrip          DriverStatus = "on_trip"
+	DriverStatusOnBreak         DriverStatus = "on_break"
+)
+
+var (
+	ErrUnknownDriverStatus     = errors.New("unknown driver status")
+	ErrInvalidDriverTransition = errors.New("invalid driver status transition")
+	ErrDriverBusy              = errors.New("driver is busy with a trip")
+)
+
+// driverTransitions lists, for every status, the statuses a driver may move to next.
+// Going offline is always possible, the app can be closed at any time.
+var driverTransitions = map[DriverStatus][]DriverStatus{
+	DriverStatusOffline: {
+		DriverStatusAvailable,
+		DriverStatusOnBreak,
+	},
+	DriverStatusAvailable: {
+		DriverStatusOffered,
+		// the offer may have been made before a restart, the accept still counts
+		DriverStatusEnRouteToPickup,
+		DriverStatusOnBreak,
+		DriverStatusOffline,
+	},
+	DriverStatusOffered: {
+		DriverStatusAvailable, // declined, timed out or withdrawn
+		DriverStatusEnRouteToPickup,
+		DriverStatusOffline,
+	},
+	DriverStatusEnRouteToPickup: {
+		DriverStatusOnTrip,
+		DriverStatusAvailable, // cancelled
+		DriverStatusOffline,
+	},
+	DriverStatusOnTrip: {
+		DriverStatusAvailable, // completed or cancelled
+		DriverStatusOffline,
+	},
+	DriverStatusOnBreak: {
+		DriverStatusAvailable,
+		DriverStatusOffline,
+	},
+}
+
+// DriverTransitionError is returned when a driver is asked to move to a status that is
+// not reachable from their current one.
+type DriverTransitionError struct {
+	DriverID string
+	From     DriverStatus
+	To       DriverStatus
+}
+
+func (e *DriverTransitionError) Error() string {
+	return fmt.Sprintf("driver %s: cannot move from %q to %q", e.DriverID, e.From, e.To)
+}
+
+func (e *DriverTransitionError) Is(target error) bool {
+	return target == ErrInvalidDriverTransition
+}
+
+// ParseDriverStatus validates a raw status string.
+func ParseDriverStatus(s string) (DriverStatus, error) {
+	status := DriverStatus(s)
+	if _, ok := driverTransitions[status]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownDriverStatus, s)
+	}
+
+	return status, nil
+}
+
+func (s DriverStatus) String() string {
+	return string(s)
+}
+
+// IsBusy reports whether the driver is tied to a trip.
+func (s DriverStatus) IsBusy() bool {
+	return s == DriverStatusOffered || s == DriverStatusEnRouteToPickup || s == DriverStatusOnTrip
+}
+
+// IsSelectable reports whether drivers may pick the status themselves, the others follow the trips.
+func (s DriverStatus) IsSelectable() bool {
+	return s == DriverStatusAvailable || s == DriverStatusOnBreak || s == DriverStatusOffline
+}
+
+// CanTransitionTo reports whether moving from s to next is allowed.
+func (s DriverStatus) CanTransitionTo(next DriverStatus) bool {
+	for _, allowed := range driverTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
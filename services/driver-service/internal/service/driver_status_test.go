@@ -0,0 +1,256 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	pb "ride-sharing/shared/proto/driver"
+)
+
+func TestDriverStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from DriverStatus
+		to   DriverStatus
+		want bool
+	}{
+		{from: DriverStatusOffline, to: DriverStatusAvailable, want: true},
+		{from: DriverStatusOffline, to: DriverStatusOffered},
+		{from: DriverStatusAvailable, to: DriverStatusOffered, want: true},
+		{from: DriverStatusAvailable, to: DriverStatusEnRouteToPickup, want: true},
+		{from: DriverStatusAvailable, to: DriverStatusOnTrip},
+		{from: DriverStatusOffered, to: DriverStatusAvailable, want: true},
+		{from: DriverStatusOffered, to: DriverStatusOnBreak},
+		{from: DriverStatusEnRouteToPickup, to: DriverStatusOnTrip, want: true},
+		{from: DriverStatusEnRouteToPickup, to: DriverStatusOffered},
+		{from: DriverStatusOnTrip, to: DriverStatusAvailable, want: true},
+		{from: DriverStatusOnTrip, to: DriverStatusOnBreak},
+		{from: DriverStatusOnBreak, to: DriverStatusOffered},
+		{from: DriverStatusOnBreak, to: DriverStatusAvailable, want: true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+			t.Errorf("%s -> %s allowed = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	// the app can be closed at any time
+	for status := range driverTransitions {
+		if status != DriverStatusOffline && !status.CanTransitionTo(DriverStatusOffline) {
+			t.Errorf("%s can't go offline", status)
+		}
+	}
+}
+
+func TestDriverStatusChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		// steps run in order, all but the last must succeed
+		steps   []func(s *Service) error
+		wantErr bool
+		// errIs is the sentinel the error must match, if any
+		errIs      error
+		wantStatus DriverStatus
+		wantTripID string
+	}{
+		{
+			name:       "offered a trip",
+			steps:      []func(s *Service) error{offer("t1")},
+			wantStatus: DriverStatusOffered,
+			wantTripID: "t1",
+		},
+		{
+			name:       "same offer twice",
+			steps:      []func(s *Service) error{offer("t1"), offer("t1")},
+			wantStatus: DriverStatusOffered,
+			wantTripID: "t1",
+		},
+		{
+			name:       "second offer while offered",
+			steps:      []func(s *Service) error{offer("t1"), offer("t2")},
+			wantErr:    true,
+			errIs:      ErrInvalidDriverTransition,
+			wantStatus: DriverStatusOffered,
+			wantTripID: "t1",
+		},
+		{
+			name:       "offer declined",
+			steps:      []func(s *Service) error{offer("t1"), releaseOffer("t1")},
+			wantStatus: DriverStatusAvailable,
+		},
+		{
+			name:       "release of another offer",
+			steps:      []func(s *Service) error{offer("t1"), releaseOffer("t2")},
+			wantStatus: DriverStatusOffered,
+			wantTripID: "t1",
+		},
+		{
+			name:       "offer accepted",
+			steps:      []func(s *Service) error{offer("t1"), assign("t1")},
+			wantStatus: DriverStatusEnRouteToPickup,
+			wantTripID: "t1",
+		},
+		{
+			name:       "picked the rider up",
+			steps:      []func(s *Service) error{assign("t1"), start("t1")},
+			wantStatus: DriverStatusOnTrip,
+			wantTripID: "t1",
+		},
+		{
+			name:       "same start twice",
+			steps:      []func(s *Service) error{assign("t1"), start("t1"), start("t1")},
+			wantStatus: DriverStatusOnTrip,
+			wantTripID: "t1",
+		},
+		{
+			name:       "start of another trip",
+			steps:      []func(s *Service) error{assign("t1"), start("t2")},
+			wantErr:    true,
+			wantStatus: DriverStatusEnRouteToPickup,
+			wantTripID: "t1",
+		},
+		{
+			name:       "trip over",
+			steps:      []func(s *Service) error{assign("t1"), start("t1"), release("t1")},
+			wantStatus: DriverStatusAvailable,
+		},
+		{
+			name:       "release of another trip",
+			steps:      []func(s *Service) error{assign("t1"), release("t2")},
+			wantStatus: DriverStatusEnRouteToPickup,
+			wantTripID: "t1",
+		},
+		{
+			name:       "break",
+			steps:      []func(s *Service) error{setStatus(DriverStatusOnBreak)},
+			wantStatus: DriverStatusOnBreak,
+		},
+		{
+			name:       "no offers on a break",
+			steps:      []func(s *Service) error{setStatus(DriverStatusOnBreak), offer("t1")},
+			wantErr:    true,
+			errIs:      ErrInvalidDriverTransition,
+			wantStatus: DriverStatusOnBreak,
+		},
+		{
+			name:       "busy driver can't go available",
+			steps:      []func(s *Service) error{assign("t1"), setStatus(DriverStatusAvailable)},
+			wantErr:    true,
+			errIs:      ErrDriverBusy,
+			wantStatus: DriverStatusEnRouteToPickup,
+			wantTripID: "t1",
+		},
+		{
+			name:       "busy driver can go offline",
+			steps:      []func(s *Service) error{assign("t1"), start("t1"), setStatus(DriverStatusOffline)},
+			wantStatus: DriverStatusOffline,
+		},
+		{
+			name:       "trip statuses can't be picked",
+			steps:      []func(s *Service) error{setStatus(DriverStatusOnTrip)},
+			wantErr:    true,
+			errIs:      ErrInvalidDriverTransition,
+			wantStatus: DriverStatusAvailable,
+		},
+		{
+			name: "unknown driver",
+			steps: []func(s *Service) error{func(s *Service) error {
+				return s.OfferTrip("nobody", "t1")
+			}},
+			wantErr:    true,
+			errIs:      ErrDriverNotFound,
+			wantStatus: DriverStatusAvailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService()
+			if _, err := s.RegisterDriver("d1", "sedan"); err != nil {
+				t.Fatalf("RegisterDriver: %v", err)
+			}
+
+			var err error
+			for i, step := range tt.steps {
+				err = step(s)
+				if err != nil && i < len(tt.steps)-1 {
+					t.Fatalf("step %d: %v", i, err)
+				}
+			}
+
+			if (err != nil) != tt.wantErr || (tt.errIs != nil && !errors.Is(err, tt.errIs)) {
+				t.Fatalf("last step error = %v, wantErr %v (%v)", err, tt.wantErr, tt.errIs)
+			}
+
+			driver := s.drivers["d1"]
+			if driver.Status != tt.wantStatus || driver.TripID != tt.wantTripID {
+				t.Fatalf("driver is %s on %q, want %s on %q", driver.Status, driver.TripID, tt.wantStatus, tt.wantTripID)
+			}
+
+			if DriverStatus(driver.Driver.Status) != driver.Status {
+				t.Fatalf("proto status = %s, want %s", driver.Driver.Status, driver.Status)
+			}
+		})
+	}
+}
+
+func offer(tripID string) func(s *Service) error {
+	return func(s *Service) error { return s.OfferTrip("d1", tripID) }
+}
+
+func releaseOffer(tripID string) func(s *Service) error {
+	return func(s *Service) error { s.ReleaseOffer("d1", tripID); return nil }
+}
+
+func assign(tripID string) func(s *Service) error {
+	return func(s *Service) error { return s.AssignTrip("d1", tripID, nil) }
+}
+
+func start(tripID string) func(s *Service) error {
+	return func(s *Service) error { return s.StartTrip("d1", tripID, nil) }
+}
+
+func release(tripID string) func(s *Service) error {
+	return func(s *Service) error { s.ReleaseDriver("d1", tripID); return nil }
+}
+
+func setStatus(status DriverStatus) func(s *Service) error {
+	return func(s *Service) error {
+		_, err := s.SetStatus("d1", status)
+		return err
+	}
+}
+
+func TestSupplyCountsAvailableDriversOnly(t *testing.T) {
+	s := NewService()
+
+	steps := map[string]func(s *Service) error{
+		"available": func(s *Service) error { return nil },
+		"offered":   func(s *Service) error { return s.OfferTrip("offered", "t1") },
+		"assigned":  func(s *Service) error { return s.AssignTrip("assigned", "t2", nil) },
+		"on-break": func(s *Service) error {
+			_, err := s.SetStatus("on-break", DriverStatusOnBreak)
+			return err
+		},
+	}
+
+	for driverID, step := range steps {
+		if _, err := s.RegisterDriver(driverID, "sedan"); err != nil {
+			t.Fatalf("RegisterDriver: %v", err)
+		}
+
+		// everyone in the same cell
+		if _, err := s.UpdateLocation(driverID, &pb.Location{Latitude: centerLat, Longitude: centerLng}); err != nil {
+			t.Fatalf("UpdateLocation: %v", err)
+		}
+
+		if err := step(s); err != nil {
+			t.Fatalf("%s: %v", driverID, err)
+		}
+	}
+
+	supply := s.SupplyByCell(5)
+	if len(supply) != 1 || supply[0].Drivers != 1 {
+		t.Fatalf("supply = %+v, want the available driver only", supply)
+	}
+}
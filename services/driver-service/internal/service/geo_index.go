@@ -0,0 +1,161 @@
+package service
+
+import (
+	"math"
+	"sort"
+
+	shareutil "ride-sharing/shared/util"
+
+	"github.com/mmcloughlin/geohash"
+)
+
+const (
+	// geohash cells of 6 characters are roughly 1.2km x 0.6km
+	defaultIndexPrecision = 6
+	// maxSearchRings caps how far we expand around the pickup cell
+	maxSearchRings = 16
+)
+
+// geoIndex buckets drivers by a geohash prefix of their Driver.Geohash.
+// It is not safe for concurrent use, the Service lock guards it.
+type geoIndex struct {
+	precision uint
+	cells     map[string]map[string]*driverInMap
+}
+
+type driverDistance struct {
+	driver     *driverInMap
+	distanceKm float64
+}
+
+func newGeoIndex(precision uint) *geoIndex {
+	return &geoIndex{
+		precision: precision,
+		cells:     make(map[string]map[string]*driverInMap),
+	}
+}
+
+func (g *geoIndex) cellOf(hash string) string {
+	if uint(len(hash)) <= g.precision {
+		return hash
+	}
+
+	return hash[:g.precision]
+}
+
+func (g *geoIndex) add(d *driverInMap) {
+	cell := g.cellOf(d.Driver.Geohash)
+
+	bucket, ok := g.cells[cell]
+	if !ok {
+		bucket = make(map[string]*driverInMap)
+		g.cells[cell] = bucket
+	}
+
+	bucket[d.Driver.Id] = d
+}
+
+func (g *geoIndex) remove(d *driverInMap) {
+	g.removeFromCell(g.cellOf(d.Driver.Geohash), d.Driver.Id)
+}
+
+// move re-buckets a driver whose geohash changed from oldHash.
+func (g *geoIndex) move(d *driverInMap, oldHash string) {
+	if g.cellOf(oldHash) == g.cellOf(d.Driver.Geohash) {
+		return
+	}
+
+	g.removeFromCell(g.cellOf(oldHash), d.Driver.Id)
+	g.add(d)
+}
+
+func (g *geoIndex) removeFromCell(cell, driverID string) {
+	bucket, ok := g.cells[cell]
+	if !ok {
+		return
+	}
+
+	delete(bucket, driverID)
+	if len(bucket) == 0 {
+		delete(g.cells, cell)
+	}
+}
+
+// nearby returns the drivers accepted by match within radiusKm of the point, closest first.
+// It starts from the pickup cell and expands to the neighbouring cells ring by ring,
+// until the rings cover the whole radius.
+func (g *geoIndex) nearby(lat, lng, radiusKm float64, match func(*driverInMap) bool) []driverDistance {
+	center := geohash.EncodeWithPrecision(lat, lng, g.precision)
+	maxRings := g.ringsFor(center, radiusKm)
+
+	visited := map[string]bool{center: true}
+	ring := []string{center}
+	var found []driverDistance
+
+	for r := 0; r <= maxRings; r++ {
+		for _, cell := range ring {
+			for _, d := range g.cells[cell] {
+				if !match(d) {
+					continue
+				}
+
+				loc := d.Driver.Location
+				dist := shareutil.HaversineKm(lat, lng, loc.Latitude, loc.Longitude)
+				if dist <= radiusKm {
+					found = append(found, driverDistance{driver: d, distanceKm: dist})
+				}
+			}
+		}
+
+		ring = expandRing(ring, visited)
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].distanceKm < found[j].distanceKm
+	})
+
+	return found
+}
+
+// ringsFor returns how many rings around center are needed to cover radiusKm.
+func (g *geoIndex) ringsFor(center string, radiusKm float64) int {
+	box := geohash.BoundingBox(center)
+	lat, _ := box.Center()
+
+	heightKm := shareutil.HaversineKm(box.MinLat, box.MinLng, box.MaxLat, box.MinLng)
+	widthKm := shareutil.HaversineKm(lat, box.MinLng, lat, box.MaxLng)
+
+	cellKm := math.Min(heightKm, widthKm)
+	if cellKm <= 0 {
+		return maxSearchRings
+	}
+
+	rings := int(math.Ceil(radiusKm / cellKm))
+	if rings < 1 {
+		rings = 1
+	}
+
+	if rings > maxSearchRings {
+		rings = maxSearchRings
+	}
+
+	return rings
+}
+
+// expandRing returns the unvisited neighbours of the given cells and marks them visited.
+func expandRing(ring []string, visited map[string]bool) []string {
+	var next []string
+
+	for _, cell := range ring {
+		for _, n := range geohash.Neighbors(cell) {
+			if visited[n] {
+				continue
+			}
+
+			visited[n] = true
+			next = append(next, n)
+		}
+	}
+
+	return next
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	pb "ride-sharing/shared/proto/driver"
+
+	"github.com/mmcloughlin/geohash"
+)
+
+const (
+	centerLat = 52.5200
+	centerLng = 13.4050
+)
+
+func indexedDriver(id string, lat, lng float64) *driverInMap {
+	return &driverInMap{
+		Driver: &pb.Driver{
+			Id:       id,
+			Geohash:  geohash.Encode(lat, lng),
+			Location: &pb.Location{Latitude: lat, Longitude: lng},
+		},
+		Status: DriverStatusAvailable,
+	}
+}
+
+func TestGeoIndexNearby(t *testing.T) {
+	// a cell of 6 characters is about 0.6km x 0.7km here, the search has to cross several of them
+	drivers := []*driverInMap{
+		indexedDriver("200m-north", centerLat+0.002, centerLng),
+		indexedDriver("1km-north", centerLat+0.01, centerLng),
+		indexedDriver("1.4km-east", centerLat, centerLng+0.02),
+		indexedDriver("3km-south", centerLat-0.03, centerLng),
+		indexedDriver("11km-north", centerLat+0.1, centerLng),
+	}
+
+	tests := []struct {
+		name     string
+		radiusKm float64
+		match    func(*driverInMap) bool
+		want     []string
+	}{
+		{
+			name:     "closest only",
+			radiusKm: 0.5,
+			want:     []string{"200m-north"},
+		},
+		{
+			name:     "neighbouring cells, closest first",
+			radiusKm: 1.5,
+			want:     []string{"200m-north", "1km-north", "1.4km-east"},
+		},
+		{
+			name:     "rings cover the radius",
+			radiusKm: 5,
+			want:     []string{"200m-north", "1km-north", "1.4km-east", "3km-south"},
+		},
+		{
+			name:     "match filters drivers",
+			radiusKm: 5,
+			match:    func(d *driverInMap) bool { return d.Driver.Id != "1km-north" },
+			want:     []string{"200m-north", "1.4km-east", "3km-south"},
+		},
+		{
+			name:     "nobody that close",
+			radiusKm: 0.1,
+		},
+	}
+
+	index := newGeoIndex(defaultIndexPrecision)
+	for _, d := range drivers {
+		index.add(d)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := tt.match
+			if match == nil {
+				match = func(*driverInMap) bool { return true }
+			}
+
+			var got []string
+			for _, found := range index.nearby(centerLat, centerLng, tt.radiusKm, match) {
+				got = append(got, found.driver.Driver.Id)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("nearby(%vkm) = %v, want %v", tt.radiusKm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeoIndexMoveAndRemove(t *testing.T) {
+	index := newGeoIndex(defaultIndexPrecision)
+
+	mover := indexedDriver("mover", centerLat, centerLng)
+	leaver := indexedDriver("leaver", centerLat+0.001, centerLng)
+	index.add(mover)
+	index.add(leaver)
+
+	oldHash := mover.Driver.Geohash
+	*mover = *indexedDriver("mover", centerLat+0.1, centerLng)
+	index.move(mover, oldHash)
+
+	index.remove(leaver)
+
+	all := func(*driverInMap) bool { return true }
+
+	if found := index.nearby(centerLat, centerLng, 1, all); len(found) != 0 {
+		t.Fatalf("nearby the old position = %+v, want nobody", found)
+	}
+
+	found := index.nearby(centerLat+0.1, centerLng, 1, all)
+	if len(found) != 1 || found[0].driver.Driver.Id != "mover" {
+		t.Fatalf("nearby the new position = %+v, want the mover", found)
+	}
+
+	if len(index.cells) != 1 {
+		t.Fatalf("index keeps %d cells, want the empty ones dropped", len(index.cells))
+	}
+}
+
+func TestRingsFor(t *testing.T) {
+	index := newGeoIndex(defaultIndexPrecision)
+	center := geohash.EncodeWithPrecision(centerLat, centerLng, defaultIndexPrecision)
+
+	tests := []struct {
+		radiusKm float64
+		want     int
+	}{
+		{radiusKm: 0, want: 1},
+		{radiusKm: 0.1, want: 1},
+		{radiusKm: 1.5, want: 3},
+		{radiusKm: 1000, want: maxSearchRings},
+	}
+
+	for _, tt := range tests {
+		if got := index.ringsFor(center, tt.radiusKm); got != tt.want {
+			t.Errorf("ringsFor(%vkm) = %d, want %d", tt.radiusKm, got, tt.want)
+		}
+	}
+}
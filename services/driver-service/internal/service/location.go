@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pb "ride-sharing/shared/proto/driver"
+
+	"github.com/mmcloughlin/geohash"
+	"google.golang.org/protobuf/proto"
+)
+
+var ErrDriverNotFound = errors.New("driver not found")
+
+// UpdateLocation moves a registered driver and tells the watchers about it.
+func (s *Service) UpdateLocation(driverID string, location *pb.Location) (*pb.Driver, error) {
+	if location == nil {
+		return nil, fmt.Errorf("location is required")
+	}
+
+	s.mu.Lock()
+
+	driver, ok := s.drivers[driverID]
+	if !ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDriverNotFound, driverID)
+	}
+
+	s.moveDriver(driver, location.Latitude, location.Longitude)
+	driver.reportedAt = time.Now()
+	driver.lastSeen = driver.reportedAt
+
+	updated := proto.Clone(driver.Driver).(*pb.Driver)
+	s.mu.Unlock()
+
+	s.watchers.notify()
+
+	return updated, nil
+}
+
+// moveDriver puts the driver at the new position. Must be called with s.mu held.
+func (s *Service) moveDriver(driver *driverInMap, lat, lng float64) {
+	oldHash := driver.Driver.Geohash
+
+	// replace rather than mutate, the old proto may still be read by whoever got it
+	moved := proto.Clone(driver.Driver).(*pb.Driver)
+	moved.Location = &pb.Location{Latitude: lat, Longitude: lng}
+	moved.Geohash = geohash.Encode(lat, lng)
+
+	driver.Driver = moved
+	s.index.move(driver, oldHash)
+}
+
+// NearbyDrivers returns copies of the drivers within radiusKm of the point, closest first.
+// Busy drivers are included, riders see every car around them, offline drivers are not.
+func (s *Service) NearbyDrivers(location *pb.Location, radiusKm float64, packageSlug string) []*pb.Driver {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	nearby := s.index.nearby(location.Latitude, location.Longitude, radiusKm, func(d *driverInMap) bool {
+		return d.Status != DriverStatusOffline && (packageSlug == "" || d.Driver.PackageSlug == packageSlug)
+	})
+
+	drivers := make([]*pb.Driver, len(nearby))
+	for i, n := range nearby {
+		drivers[i] = proto.Clone(n.driver.Driver).(*pb.Driver)
+	}
+
+	return drivers
+}
+
+// WatchLocations returns a channel that receives a value whenever drivers moved, joined or left.
+// Changes happening while the watcher is busy are folded into one. Call stop when done.
+func (s *Service) WatchLocations() (changes <-chan struct{}, stop func()) {
+	return s.watchers.add()
+}
+
+// locationWatchers fans the location changes out to the WatchNearbyDrivers streams.
+type locationWatchers struct {
+	chans map[chan struct{}]bool
+	mu    sync.Mutex
+}
+
+func newLocationWatchers() *locationWatchers {
+	return &locationWatchers{
+		chans: make(map[chan struct{}]bool),
+	}
+}
+
+func (w *locationWatchers) add() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	w.mu.Lock()
+	w.chans[ch] = true
+	w.mu.Unlock()
+
+	return ch, func() {
+		w.mu.Lock()
+		delete(w.chans, ch)
+		w.mu.Unlock()
+	}
+}
+
+func (w *locationWatchers) notify() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for ch := range w.chans {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// a change is already pending for this watcher
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	pb "ride-sharing/shared/proto/driver"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type ReaperConfig struct {
+	// TTL is how long a driver can go without a heartbeat before they are marked offline
+	TTL      time.Duration
+	Interval time.Duration
+}
+
+func DefaultReaperConfig() ReaperConfig {
+	return ReaperConfig{
+		TTL:      30 * time.Second,
+		Interval: 10 * time.Second,
+	}
+}
+
+// StaleDriver is a driver the reaper marked offline, with what they were doing before.
+type StaleDriver struct {
+	DriverID string
+	TripID   string
+	Status   DriverStatus
+	LastSeen time.Time
+}
+
+// Heartbeat records that the driver is still connected. A driver the reaper marked offline
+// goes back to their trip, or is available again, one who went offline themselves stays offline.
+func (s *Service) Heartbeat(driverID string) (*pb.Driver, error) {
+	s.mu.Lock()
+
+	driver, ok := s.drivers[driverID]
+	if !ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDriverNotFound, driverID)
+	}
+
+	driver.lastSeen = time.Now()
+
+	revived := driver.stale
+	if revived {
+		driver.stale = false
+		if err := s.revive(driver); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+
+		log.Printf("Driver %s is back online", driverID)
+	}
+
+	updated := proto.Clone(driver.Driver).(*pb.Driver)
+	s.mu.Unlock()
+
+	if revived {
+		s.watchers.notify()
+	}
+
+	return updated, nil
+}
+
+// MarkStale takes the drivers not seen since seenBefore offline and returns them.
+func (s *Service) MarkStale(seenBefore time.Time) []StaleDriver {
+	s.mu.Lock()
+
+	var stale []StaleDriver
+	for _, driver := range s.drivers {
+		if driver.Status == DriverStatusOffline || !driver.lastSeen.Before(seenBefore) {
+			continue
+		}
+
+		stale = append(stale, StaleDriver{
+			DriverID: driver.Driver.Id,
+			TripID:   driver.TripID,
+			Status:   driver.Status,
+			LastSeen: driver.lastSeen,
+		})
+
+		status, tripID, target := driver.Status, driver.TripID, driver.Target
+
+		if err := s.transition(driver, DriverStatusOffline); err != nil {
+			// every status can go offline
+			log.Printf("Failed to mark driver %s offline: %v", driver.Driver.Id, err)
+			continue
+		}
+
+		driver.stale = true
+
+		// trip service still has them on the trip, a lost connection doesn't end it.
+		// An offer is not kept, the dispatcher moves on to the next driver.
+		if status == DriverStatusEnRouteToPickup || status == DriverStatusOnTrip {
+			driver.resumeStatus = status
+			driver.TripID = tripID
+			driver.Target = target
+		}
+	}
+
+	s.mu.Unlock()
+
+	if len(stale) > 0 {
+		s.watchers.notify()
+	}
+
+	return stale
+}
+
+// revive brings a driver the reaper took offline back to the trip they kept, or to available.
+// Must be called with s.mu held.
+func (s *Service) revive(driver *driverInMap) error {
+	if driver.resumeStatus == "" {
+		return s.transition(driver, DriverStatusAvailable)
+	}
+
+	// not a transition the driver can make, offline -> busy only happens here
+	updated := proto.Clone(driver.Driver).(*pb.Driver)
+	updated.Status = string(driver.resumeStatus)
+
+	driver.Driver = updated
+	driver.Status = driver.resumeStatus
+	driver.resumeStatus = ""
+
+	return nil
+}
+
+// RunReaper marks the drivers without a heartbeat for cfg.TTL offline every interval until
+// ctx is done, typically because their gateway died before unregistering them.
+func (s *Service) RunReaper(ctx context.Context, cfg ReaperConfig, onOffline func(context.Context, StaleDriver)) {
+	if cfg.TTL <= 0 || cfg.Interval <= 0 {
+		log.Printf("Driver reaper needs a positive TTL and interval, got %s and %s", cfg.TTL, cfg.Interval)
+		return
+	}
+
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, driver := range s.MarkStale(time.Now().Add(-cfg.TTL)) {
+			log.Printf("Driver %s not seen since %s, marked offline", driver.DriverID, driver.LastSeen.Format(time.RFC3339))
+
+			if onOffline != nil {
+				onOffline(ctx, driver)
+			}
+		}
+	}
+}
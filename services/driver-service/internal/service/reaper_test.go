@@ -0,0 +1,160 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMarkStaleAndHeartbeat(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup moves the registered driver "d1" into the status under test
+		setup      func(t *testing.T, s *Service)
+		afterStale func(t *testing.T, s *Service)
+		wantStatus DriverStatus
+		wantTripID string
+	}{
+		{
+			name:       "available driver comes back available",
+			setup:      func(t *testing.T, s *Service) {},
+			wantStatus: DriverStatusAvailable,
+		},
+		{
+			name: "offer is dropped",
+			setup: func(t *testing.T, s *Service) {
+				mustDo(t, s.OfferTrip("d1", "t1"))
+			},
+			wantStatus: DriverStatusAvailable,
+		},
+		{
+			name: "driver on the way to the pickup keeps the trip",
+			setup: func(t *testing.T, s *Service) {
+				mustDo(t, s.AssignTrip("d1", "t1", nil))
+			},
+			wantStatus: DriverStatusEnRouteToPickup,
+			wantTripID: "t1",
+		},
+		{
+			name: "driver on the trip keeps it",
+			setup: func(t *testing.T, s *Service) {
+				mustDo(t, s.AssignTrip("d1", "t1", nil))
+				mustDo(t, s.StartTrip("d1", "t1", nil))
+			},
+			wantStatus: DriverStatusOnTrip,
+			wantTripID: "t1",
+		},
+		{
+			name: "trip started while offline",
+			setup: func(t *testing.T, s *Service) {
+				mustDo(t, s.AssignTrip("d1", "t1", nil))
+			},
+			afterStale: func(t *testing.T, s *Service) {
+				mustDo(t, s.StartTrip("d1", "t1", nil))
+			},
+			wantStatus: DriverStatusOnTrip,
+			wantTripID: "t1",
+		},
+		{
+			name: "trip ended while offline",
+			setup: func(t *testing.T, s *Service) {
+				mustDo(t, s.AssignTrip("d1", "t1", nil))
+			},
+			afterStale: func(t *testing.T, s *Service) {
+				s.ReleaseDriver("d1", "t1")
+			},
+			wantStatus: DriverStatusAvailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService()
+			if _, err := s.RegisterDriver("d1", "sedan"); err != nil {
+				t.Fatalf("RegisterDriver: %v", err)
+			}
+
+			tt.setup(t, s)
+
+			stale := s.MarkStale(time.Now().Add(time.Minute))
+			if len(stale) != 1 {
+				t.Fatalf("MarkStale returned %d drivers, want 1", len(stale))
+			}
+
+			if got := s.drivers["d1"].Status; got != DriverStatusOffline {
+				t.Fatalf("status after MarkStale = %s, want offline", got)
+			}
+
+			if ids := s.FindAvailableDrivers("sedan", nil, DefaultSearchRadiusKm); len(ids) != 0 {
+				t.Fatalf("stale driver is offered trips: %v", ids)
+			}
+
+			if tt.afterStale != nil {
+				tt.afterStale(t, s)
+			}
+
+			driver, err := s.Heartbeat("d1")
+			if err != nil {
+				t.Fatalf("Heartbeat: %v", err)
+			}
+
+			if DriverStatus(driver.Status) != tt.wantStatus {
+				t.Fatalf("status after Heartbeat = %s, want %s", driver.Status, tt.wantStatus)
+			}
+
+			if got := s.drivers["d1"].TripID; got != tt.wantTripID {
+				t.Fatalf("TripID after Heartbeat = %q, want %q", got, tt.wantTripID)
+			}
+		})
+	}
+}
+
+func TestHeartbeatKeepsDriverOfflineByChoice(t *testing.T) {
+	s := NewService()
+	if _, err := s.RegisterDriver("d1", "sedan"); err != nil {
+		t.Fatalf("RegisterDriver: %v", err)
+	}
+
+	if _, err := s.SetStatus("d1", DriverStatusOffline); err != nil {
+		t.Fatalf("SetStatus: %v", err)
+	}
+
+	if stale := s.MarkStale(time.Now().Add(time.Minute)); len(stale) != 0 {
+		t.Fatalf("MarkStale took an offline driver: %+v", stale)
+	}
+
+	driver, err := s.Heartbeat("d1")
+	if err != nil {
+		t.Fatalf("Heartbeat: %v", err)
+	}
+
+	if DriverStatus(driver.Status) != DriverStatusOffline {
+		t.Fatalf("status = %s, want offline", driver.Status)
+	}
+}
+
+func TestSetStatusOfStaleBusyDriver(t *testing.T) {
+	s := NewService()
+	if _, err := s.RegisterDriver("d1", "sedan"); err != nil {
+		t.Fatalf("RegisterDriver: %v", err)
+	}
+
+	mustDo(t, s.AssignTrip("d1", "t1", nil))
+	s.MarkStale(time.Now().Add(time.Minute))
+
+	// back with the trip, they can't just go available
+	if _, err := s.SetStatus("d1", DriverStatusAvailable); err == nil {
+		t.Fatal("SetStatus(available) of a driver back on a trip succeeded")
+	}
+
+	if got := s.drivers["d1"].Status; got != DriverStatusEnRouteToPickup {
+		t.Fatalf("status = %s, want %s", got, DriverStatusEnRouteToPickup)
+	}
+}
+
+func mustDo(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,57 +1,132 @@
 package service
 
 import (
+	"fmt"
 	"log"
 	math "math/rand/v2"
 	"ride-sharing/services/driver-service/utils"
 	pb "ride-sharing/shared/proto/driver"
 	shareutil "ride-sharing/shared/util"
 	"sync"
+	"time"
 
 	"github.com/mmcloughlin/geohash"
+	"google.golang.org/protobuf/proto"
 )
 
 type driverInMap struct {
 	Driver *pb.Driver
-	// Index int
-	// TODO: route
+	Status DriverStatus
+	// TripID is the trip the driver was offered or is busy with, empty when free
+	TripID string
+	// Target is where the driver is heading for their trip, the pickup and then the drop-off
+	Target *pb.Location
+
+	// Route is the predefined route the simulation drives along, as [lat, lng] points
+	Route [][]float64
+	// waypoint is the route point the simulation heads to next, direction +1 or -1
+	waypoint  int
+	direction int
+	// reportedAt is when the driver last sent their own location, the simulation leaves them alone for a while
+	reportedAt time.Time
+	// lastSeen is the last heartbeat, location or status from the driver, stale when the reaper took them offline
+	lastSeen time.Time
+	stale    bool
+	// resumeStatus is the busy status a stale driver goes back to, they keep TripID and Target meanwhile
+	resumeStatus DriverStatus
 }
 
 type Service struct {
-	drivers []*driverInMap
-	mu      sync.RWMutex
+	drivers  map[string]*driverInMap
+	index    *geoIndex
+	watchers *locationWatchers
+	mu       sync.RWMutex
 }
 
+const (
+	// DefaultSearchRadiusKm is how far from the pickup we look for drivers
+	DefaultSearchRadiusKm = 5.0
+)
+
 func NewService() *Service {
 	return &Service{
-		drivers: make([]*driverInMap, 0),
+		drivers:  make(map[string]*driverInMap),
+		index:    newGeoIndex(defaultIndexPrecision),
+		watchers: newLocationWatchers(),
 	}
 }
 
-func (s *Service) FindAvailableDrivers(packageType string) []string {
-	log.Printf("calling findAvailableDrivers func<<<-")
-	var matchingDrivers []string
+// FindAvailableDrivers returns the IDs of the drivers of the given package within radiusKm
+// of the pickup, closest first. Without a pickup every driver of the package is returned.
+func (s *Service) FindAvailableDrivers(packageType string, pickup *pb.Location, radiusKm float64) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	log.Printf("packageType-> %v", packageType)
+	matchesPackage := func(d *driverInMap) bool {
+		return d.Driver.PackageSlug == packageType && d.Status == DriverStatusAvailable
+	}
 
-	log.Printf("list of drivers %v", s.drivers)
+	matchingDrivers := []string{}
 
-	for _, driver := range s.drivers {
-		if driver.Driver.PackageSlug == packageType {
-			matchingDrivers = append(matchingDrivers, driver.Driver.Id)
+	if pickup == nil {
+		for _, driver := range s.drivers {
+			if matchesPackage(driver) {
+				matchingDrivers = append(matchingDrivers, driver.Driver.Id)
+			}
 		}
+
+		return matchingDrivers
 	}
 
-	if len(matchingDrivers) == 0 {
-		return []string{}
+	for _, nearby := range s.index.nearby(pickup.Latitude, pickup.Longitude, radiusKm, matchesPackage) {
+		matchingDrivers = append(matchingDrivers, nearby.driver.Driver.Id)
 	}
 
+	log.Printf("Found %d %s drivers within %.1fkm", len(matchingDrivers), packageType, radiusKm)
+
 	return matchingDrivers
 }
 
+// CellSupply is the number of drivers of a package in a geohash cell.
+type CellSupply struct {
+	Geohash     string
+	PackageSlug string
+	Drivers     int
+}
+
+// SupplyByCell counts the available drivers per geohash cell of the given precision and package.
+func (s *Service) SupplyByCell(precision uint) []CellSupply {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	type key struct{ cell, pkg string }
+	counts := make(map[key]int)
+
+	for _, d := range s.drivers {
+		if d.Status != DriverStatusAvailable {
+			continue
+		}
+
+		cell := d.Driver.Geohash
+		if uint(len(cell)) > precision {
+			cell = cell[:precision]
+		}
+
+		counts[key{cell, d.Driver.PackageSlug}]++
+	}
+
+	supply := make([]CellSupply, 0, len(counts))
+	for k, n := range counts {
+		supply = append(supply, CellSupply{Geohash: k.cell, PackageSlug: k.pkg, Drivers: n})
+	}
+
+	return supply
+}
+
 func (s *Service) RegisterDriver(driverId string, packageSlug string) (*pb.Driver, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	defer s.watchers.notify()
 
 	randomIndex := math.IntN(len(utils.PredefinedRoutes))
 	randomRoute := utils.PredefinedRoutes[randomIndex]
@@ -70,24 +145,225 @@ func (s *Service) RegisterDriver(driverId string, packageSlug string) (*pb.Drive
 		PackageSlug:    packageSlug,
 		ProfilePicture: randomAvatar,
 		CarPlate:       randomPlate,
+		Status:         string(DriverStatusAvailable),
+	}
+
+	if existing, ok := s.drivers[driverId]; ok {
+		s.index.remove(existing)
+	}
+
+	entry := &driverInMap{
+		Driver:    driver,
+		Status:    DriverStatusAvailable,
+		Route:     randomRoute,
+		waypoint:  1 % len(randomRoute),
+		direction: 1,
+		lastSeen:  time.Now(),
 	}
 
-	s.drivers = append(s.drivers, &driverInMap{
-		Driver: driver,
-	})
+	s.drivers[driverId] = entry
+	s.index.add(entry)
 
-	log.Printf("register driver %v:", s.drivers)
+	log.Printf("register driver %v:", driverId)
 
 	return driver, nil
 }
 
+// SetStatus is the driver going available, on a break or offline. The other statuses
+// follow the trip, so a busy driver can only go offline.
+func (s *Service) SetStatus(driverID string, next DriverStatus) (*pb.Driver, error) {
+	if !next.IsSelectable() {
+		return nil, fmt.Errorf("%w: drivers can't pick %q", ErrInvalidDriverTransition, next)
+	}
+
+	s.mu.Lock()
+
+	driver, ok := s.drivers[driverID]
+	if !ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDriverNotFound, driverID)
+	}
+
+	driver.lastSeen = time.Now()
+
+	// they are back, with the trip they may still have
+	if driver.stale {
+		driver.stale = false
+		if err := s.revive(driver); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	if driver.Status.IsBusy() && next != DriverStatusOffline {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrDriverBusy, driver.TripID)
+	}
+
+	if driver.Status != next {
+		if err := s.transition(driver, next); err != nil {
+			s.mu.Unlock()
+			return nil, err
+		}
+	}
+
+	updated := proto.Clone(driver.Driver).(*pb.Driver)
+	s.mu.Unlock()
+
+	s.watchers.notify()
+
+	return updated, nil
+}
+
+// OfferTrip holds an available driver for the trip while they think about it.
+func (s *Service) OfferTrip(driverID, tripID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	driver, ok := s.drivers[driverID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrDriverNotFound, driverID)
+	}
+
+	if driver.Status == DriverStatusOffered && driver.TripID == tripID {
+		return nil
+	}
+
+	if err := s.transition(driver, DriverStatusOffered); err != nil {
+		return err
+	}
+
+	driver.TripID = tripID
+
+	return nil
+}
+
+// ReleaseOffer makes the driver available again after they declined, missed or lost the offer.
+func (s *Service) ReleaseOffer(driverID, tripID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if driver, ok := s.drivers[driverID]; ok && driver.Status == DriverStatusOffered && driver.TripID == tripID {
+		_ = s.transition(driver, DriverStatusAvailable)
+	}
+}
+
+// AssignTrip sends the driver to the pickup, they won't be offered other trips until released.
+// The pickup, when known, is where the simulation drives them.
+func (s *Service) AssignTrip(driverID, tripID string, pickup *pb.Location) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	driver, ok := s.drivers[driverID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrDriverNotFound, driverID)
+	}
+
+	if driver.Status == DriverStatusEnRouteToPickup && driver.TripID == tripID {
+		return nil
+	}
+
+	if err := s.transition(driver, DriverStatusEnRouteToPickup); err != nil {
+		return err
+	}
+
+	driver.TripID = tripID
+	driver.Target = pickup
+
+	return nil
+}
+
+// StartTrip records that the driver picked the rider up and is heading to the drop-off.
+func (s *Service) StartTrip(driverID, tripID string, dropoff *pb.Location) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	driver, ok := s.drivers[driverID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrDriverNotFound, driverID)
+	}
+
+	if driver.TripID != tripID {
+		return fmt.Errorf("driver %s is not assigned to trip %s", driverID, tripID)
+	}
+
+	// the started event may come again
+	if driver.Status == DriverStatusOnTrip {
+		return nil
+	}
+
+	if driver.stale && driver.resumeStatus != "" {
+		// on the trip once their heartbeats are back
+		driver.resumeStatus = DriverStatusOnTrip
+		driver.Target = dropoff
+		return nil
+	}
+
+	if err := s.transition(driver, DriverStatusOnTrip); err != nil {
+		return err
+	}
+
+	driver.Target = dropoff
+
+	return nil
+}
+
+// ReleaseDriver makes the driver available again, unless they moved on to another trip already.
+// A stale driver stays offline, without the trip to come back to.
+func (s *Service) ReleaseDriver(driverID, tripID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	driver, ok := s.drivers[driverID]
+	if !ok || driver.TripID != tripID {
+		return
+	}
+
+	switch {
+	case driver.Status.IsBusy():
+		_ = s.transition(driver, DriverStatusAvailable)
+
+	case driver.resumeStatus != "":
+		driver.TripID = ""
+		driver.Target = nil
+		driver.resumeStatus = ""
+	}
+}
+
+// transition moves the driver to the next status, dropping their trip once they are no longer busy.
+// Must be called with s.mu held.
+func (s *Service) transition(driver *driverInMap, next DriverStatus) error {
+	if !driver.Status.CanTransitionTo(next) {
+		return &DriverTransitionError{DriverID: driver.Driver.Id, From: driver.Status, To: next}
+	}
+
+	// replace rather than mutate, the old proto may still be read by whoever got it
+	updated := proto.Clone(driver.Driver).(*pb.Driver)
+	updated.Status = string(next)
+
+	driver.Driver = updated
+	driver.Status = next
+	driver.resumeStatus = ""
+
+	if !next.IsBusy() {
+		driver.TripID = ""
+		driver.Target = nil
+	}
+
+	return nil
+}
+
 func (s *Service) UnregisterDriver(driverId string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for i, driver := range s.drivers {
-		if driver.Driver.Id == driverId {
-			s.drivers = append(s.drivers[:i], s.drivers[i+1:]...)
-		}
+	driver, ok := s.drivers[driverId]
+	if !ok {
+		return
 	}
+
+	s.index.remove(driver)
+	delete(s.drivers, driverId)
+
+	s.watchers.notify()
 }
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	pb "ride-sharing/shared/proto/driver"
+	shareutil "ride-sharing/shared/util"
+)
+
+type SimulationConfig struct {
+	// Tick is how often the drivers move
+	Tick     time.Duration
+	SpeedKmh float64
+	// ReportedGrace is how long a driver who sent their own location is left alone
+	ReportedGrace time.Duration
+}
+
+func DefaultSimulationConfig() SimulationConfig {
+	return SimulationConfig{
+		Tick:          2 * time.Second,
+		SpeedKmh:      30,
+		ReportedGrace: 30 * time.Second,
+	}
+}
+
+// Simulate moves the drivers every tick until ctx is done, for demos without real phones.
+// Free drivers go back and forth along their predefined route, drivers with a trip
+// head straight to the pickup, then to the drop-off, and wait there. Drivers on a break stay put.
+// onMoved is called with every driver that moved, to announce the new location.
+func (s *Service) Simulate(ctx context.Context, cfg SimulationConfig, onMoved func(context.Context, *pb.Driver)) {
+	if cfg.Tick <= 0 || cfg.SpeedKmh <= 0 {
+		log.Printf("Driver simulation needs a positive tick and speed, got %s and %.0fkm/h", cfg.Tick, cfg.SpeedKmh)
+		return
+	}
+
+	log.Printf("Simulating driver movement at %.0fkm/h every %s", cfg.SpeedKmh, cfg.Tick)
+
+	ticker := time.NewTicker(cfg.Tick)
+	defer ticker.Stop()
+
+	stepKm := cfg.SpeedKmh * cfg.Tick.Hours()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, driver := range s.simulateStep(stepKm, time.Now().Add(-cfg.ReportedGrace)) {
+			if onMoved != nil {
+				onMoved(ctx, driver)
+			}
+		}
+	}
+}
+
+// simulateStep moves the drivers and returns the ones that moved.
+func (s *Service) simulateStep(stepKm float64, reportedBefore time.Time) []*pb.Driver {
+	s.mu.Lock()
+
+	var moved []*pb.Driver
+	for _, d := range s.drivers {
+		if d.Status == DriverStatusOffline || d.Status == DriverStatusOnBreak || d.reportedAt.After(reportedBefore) {
+			continue
+		}
+
+		if s.advance(d, stepKm) {
+			// moveDriver replaced the proto, nobody mutates this one
+			moved = append(moved, d.Driver)
+		}
+	}
+
+	s.mu.Unlock()
+
+	if len(moved) > 0 {
+		s.watchers.notify()
+	}
+
+	return moved
+}
+
+// advance drives stepKm towards the next target, passing route points on the way.
+// It reports whether the driver moved. Must be called with s.mu held.
+func (s *Service) advance(d *driverInMap, stepKm float64) bool {
+	loc := d.Driver.GetLocation()
+	if loc == nil {
+		return false
+	}
+
+	lat, lng := loc.Latitude, loc.Longitude
+	remaining := stepKm
+
+	// a route point is passed at most once per step, so short routes can't spin forever
+	for i := 0; remaining > 0 && i <= len(d.Route); i++ {
+		targetLat, targetLng, ok := d.target()
+		if !ok {
+			break
+		}
+
+		dist := shareutil.HaversineKm(lat, lng, targetLat, targetLng)
+		if dist > remaining {
+			// good enough on city distances
+			f := remaining / dist
+			lat += (targetLat - lat) * f
+			lng += (targetLng - lng) * f
+			break
+		}
+
+		lat, lng = targetLat, targetLng
+		remaining -= dist
+
+		if d.Target != nil {
+			// arrived, wait for the rider or for the trip to be completed
+			break
+		}
+
+		d.nextWaypoint()
+	}
+
+	if lat == loc.Latitude && lng == loc.Longitude {
+		return false
+	}
+
+	s.moveDriver(d, lat, lng)
+
+	return true
+}
+
+func (d *driverInMap) target() (lat, lng float64, ok bool) {
+	if d.Target != nil {
+		return d.Target.Latitude, d.Target.Longitude, true
+	}
+
+	if len(d.Route) < 2 {
+		return 0, 0, false
+	}
+
+	p := d.Route[d.waypoint]
+
+	return p[0], p[1], true
+}
+
+// nextWaypoint moves on to the next route point, turning around at both ends.
+func (d *driverInMap) nextWaypoint() {
+	next := d.waypoint + d.direction
+	if next < 0 || next >= len(d.Route) {
+		d.direction = -d.direction
+		next = d.waypoint + d.direction
+	}
+
+	d.waypoint = next
+}
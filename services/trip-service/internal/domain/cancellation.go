@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	pb "ride-sharing/shared/proto/trip"
+)
+
+var (
+	ErrInvalidCancellation    = errors.New("invalid cancellation")
+	ErrCancellationNotAllowed = errors.New("cancellation not allowed")
+)
+
+type CancellationActor string
+
+const (
+	CancelledByRider  CancellationActor = "rider"
+	CancelledByDriver CancellationActor = "driver"
+	CancelledBySystem CancellationActor = "system"
+)
+
+type CancellationReason string
+
+const (
+	CancelReasonChangedMind    CancellationReason = "changed_mind"
+	CancelReasonDriverTooFar   CancellationReason = "driver_too_far"
+	CancelReasonWaitedTooLong  CancellationReason = "waited_too_long"
+	CancelReasonRiderNoShow    CancellationReason = "rider_no_show"
+	CancelReasonVehicleIssue   CancellationReason = "vehicle_issue"
+	CancelReasonPaymentFailed  CancellationReason = "payment_failed"
+	CancelReasonDispatchFailed CancellationReason = "dispatch_failed"
+	CancelReasonOther          CancellationReason = "other"
+)
+
+// cancellationReasons lists which reasons each actor can give.
+var cancellationReasons = map[CancellationActor][]CancellationReason{
+	CancelledByRider:  {CancelReasonChangedMind, CancelReasonDriverTooFar, CancelReasonWaitedTooLong, CancelReasonOther},
+	CancelledByDriver: {CancelReasonRiderNoShow, CancelReasonVehicleIssue, CancelReasonOther},
+	CancelledBySystem: {CancelReasonPaymentFailed, CancelReasonDispatchFailed, CancelReasonOther},
+}
+
+// CancellationPolicy decides what a cancellation costs the rider.
+type CancellationPolicy struct {
+	FeeCents int64
+	// FreeWindow is how long after a driver accepted the rider can still cancel for free
+	FreeWindow time.Duration
+}
+
+func DefaultCancellationPolicy() CancellationPolicy {
+	return CancellationPolicy{
+		FeeCents:   500,
+		FreeWindow: 2 * time.Minute,
+	}
+}
+
+type CancellationRequest struct {
+	Actor CancellationActor
+	// ActorID is the rider or driver id, empty for the system
+	ActorID string
+	Reason  CancellationReason
+	Note    string
+}
+
+// TripCancellation records who cancelled a trip, why, and what it cost the rider.
+type TripCancellation struct {
+	Actor    CancellationActor  `bson:"actor"`
+	ActorID  string             `bson:"actorID"`
+	Reason   CancellationReason `bson:"reason"`
+	Note     string             `bson:"note"`
+	FeeCents int64              `bson:"feeCents"`
+	At       time.Time          `bson:"at"`
+}
+
+func (c *TripCancellation) ToProto() *pb.TripCancellation {
+	return &pb.TripCancellation{
+		Actor:       string(c.Actor),
+		ActorID:     c.ActorID,
+		Reason:      string(c.Reason),
+		Note:        c.Note,
+		FeeInCents:  float64(c.FeeCents),
+		CancelledAt: c.At.UTC().Format(time.RFC3339),
+	}
+}
+
+func (r CancellationRequest) validate() error {
+	reasons, ok := cancellationReasons[r.Actor]
+	if !ok {
+		return fmt.Errorf("%w: unknown actor %q", ErrInvalidCancellation, r.Actor)
+	}
+
+	if r.Actor != CancelledBySystem && r.ActorID == "" {
+		return fmt.Errorf("%w: %s id is required", ErrInvalidCancellation, r.Actor)
+	}
+
+	for _, reason := range reasons {
+		if reason == r.Reason {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %s can't cancel with reason %q", ErrInvalidCancellation, r.Actor, r.Reason)
+}
+
+// Cancel cancels the trip on behalf of the actor.
+//
+// Riders can cancel until the trip starts, and pay the fee once a driver has been on
+// their way for longer than the free window. Only the assigned driver can cancel, before
+// the trip starts, and a no-show charges the rider. The system can cancel any open trip.
+func (t *TripModel) Cancel(req CancellationRequest, policy CancellationPolicy, at time.Time) error {
+	if err := req.validate(); err != nil {
+		return err
+	}
+
+	if t.Status.IsTerminal() {
+		return &TransitionError{TripID: t.ID.Hex(), From: t.Status, To: TripStatusCancelled}
+	}
+
+	var fee int64
+
+	switch req.Actor {
+	case CancelledByRider:
+		if req.ActorID != t.UserID {
+			return ErrTripOwnerMismatch
+		}
+
+		if t.Status == TripStatusInProgress {
+			return fmt.Errorf("%w: the trip already started", ErrCancellationNotAllowed)
+		}
+
+		if acceptedAt, ok := t.StatusChangedAt(TripStatusAccepted); ok && at.Sub(acceptedAt) > policy.FreeWindow {
+			fee = policy.FeeCents
+		}
+
+	case CancelledByDriver:
+		if t.Driver == nil || t.Driver.Id != req.ActorID {
+			return fmt.Errorf("%w: driver %s is not assigned to the trip", ErrCancellationNotAllowed, req.ActorID)
+		}
+
+		if t.Status != TripStatusAccepted && t.Status != TripStatusEnRoute {
+			return fmt.Errorf("%w: drivers can't cancel a %s trip", ErrCancellationNotAllowed, t.Status)
+		}
+
+		if req.Reason == CancelReasonRiderNoShow {
+			if t.Status != TripStatusEnRoute {
+				return fmt.Errorf("%w: no-show before the driver set off for the pickup", ErrCancellationNotAllowed)
+			}
+
+			fee = policy.FeeCents
+		}
+	}
+
+	if err := t.Transition(TripStatusCancelled, at); err != nil {
+		return err
+	}
+
+	t.Cancellation = &TripCancellation{
+		Actor:    req.Actor,
+		ActorID:  req.ActorID,
+		Reason:   req.Reason,
+		Note:     req.Note,
+		FeeCents: fee,
+		At:       at,
+	}
+
+	return nil
+}
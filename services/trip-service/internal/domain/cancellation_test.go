@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "ride-sharing/shared/proto/trip"
+)
+
+func TestCancel(t *testing.T) {
+	policy := CancellationPolicy{FeeCents: 500, FreeWindow: 2 * time.Minute}
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		status TripStatus
+		// acceptedAgo is how long before the cancellation the driver accepted, zero if they didn't
+		acceptedAgo time.Duration
+		req         CancellationRequest
+		wantErr     error
+		wantFee     int64
+	}{
+		{
+			name:   "rider before a driver accepted",
+			status: TripStatusPending,
+			req:    CancellationRequest{Actor: CancelledByRider, ActorID: "u1", Reason: CancelReasonChangedMind},
+		},
+		{
+			name:        "rider within the free window",
+			status:      TripStatusAccepted,
+			acceptedAgo: time.Minute,
+			req:         CancellationRequest{Actor: CancelledByRider, ActorID: "u1", Reason: CancelReasonDriverTooFar},
+		},
+		{
+			name:        "rider after the free window",
+			status:      TripStatusEnRoute,
+			acceptedAgo: 5 * time.Minute,
+			req:         CancellationRequest{Actor: CancelledByRider, ActorID: "u1", Reason: CancelReasonWaitedTooLong},
+			wantFee:     500,
+		},
+		{
+			name:    "rider of another trip",
+			status:  TripStatusPending,
+			req:     CancellationRequest{Actor: CancelledByRider, ActorID: "u2", Reason: CancelReasonChangedMind},
+			wantErr: ErrTripOwnerMismatch,
+		},
+		{
+			name:    "rider once the trip started",
+			status:  TripStatusInProgress,
+			req:     CancellationRequest{Actor: CancelledByRider, ActorID: "u1", Reason: CancelReasonChangedMind},
+			wantErr: ErrCancellationNotAllowed,
+		},
+		{
+			name:        "rider no-show once the driver set off",
+			status:      TripStatusEnRoute,
+			acceptedAgo: time.Minute,
+			req:         CancellationRequest{Actor: CancelledByDriver, ActorID: "d1", Reason: CancelReasonRiderNoShow},
+			wantFee:     500,
+		},
+		{
+			name:        "rider no-show before the driver set off",
+			status:      TripStatusAccepted,
+			acceptedAgo: time.Minute,
+			req:         CancellationRequest{Actor: CancelledByDriver, ActorID: "d1", Reason: CancelReasonRiderNoShow},
+			wantErr:     ErrCancellationNotAllowed,
+		},
+		{
+			name:        "driver with a broken car",
+			status:      TripStatusAccepted,
+			acceptedAgo: time.Minute,
+			req:         CancellationRequest{Actor: CancelledByDriver, ActorID: "d1", Reason: CancelReasonVehicleIssue},
+		},
+		{
+			name:        "driver not assigned",
+			status:      TripStatusEnRoute,
+			acceptedAgo: time.Minute,
+			req:         CancellationRequest{Actor: CancelledByDriver, ActorID: "d2", Reason: CancelReasonVehicleIssue},
+			wantErr:     ErrCancellationNotAllowed,
+		},
+		{
+			name:    "driver reason given by a rider",
+			status:  TripStatusPending,
+			req:     CancellationRequest{Actor: CancelledByRider, ActorID: "u1", Reason: CancelReasonRiderNoShow},
+			wantErr: ErrInvalidCancellation,
+		},
+		{
+			name:   "system",
+			status: TripStatusDriverOffered,
+			req:    CancellationRequest{Actor: CancelledBySystem, Reason: CancelReasonDispatchFailed},
+		},
+		{
+			name:    "already over",
+			status:  TripStatusCompleted,
+			req:     CancellationRequest{Actor: CancelledBySystem, Reason: CancelReasonOther},
+			wantErr: ErrInvalidTripTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trip := &TripModel{UserID: "u1", Status: tt.status, Driver: &pb.TripDriver{}}
+			if tt.acceptedAgo > 0 {
+				trip.Driver.Id = "d1"
+				trip.StatusHistory = []TripStatusChange{{Status: TripStatusAccepted, At: now.Add(-tt.acceptedAgo)}}
+			}
+
+			err := trip.Cancel(tt.req, policy, now)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Cancel error = %v, want %v", err, tt.wantErr)
+				}
+
+				if trip.Status != tt.status || trip.Cancellation != nil {
+					t.Fatalf("failed cancellation changed the trip: %+v", trip)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Cancel: %v", err)
+			}
+
+			if trip.Status != TripStatusCancelled {
+				t.Fatalf("status = %s, want %s", trip.Status, TripStatusCancelled)
+			}
+
+			if trip.Cancellation.FeeCents != tt.wantFee || trip.Cancellation.Actor != tt.req.Actor {
+				t.Fatalf("cancellation = %+v, want a fee of %d", trip.Cancellation, tt.wantFee)
+			}
+		})
+	}
+}
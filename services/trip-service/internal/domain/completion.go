@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"time"
+
+	pb "ride-sharing/shared/proto/trip"
+)
+
+// FareAdjustment changes the quoted price after the ride, negative amounts are refunds.
+type FareAdjustment struct {
+	Reason      string `bson:"reason"`
+	AmountCents int64  `bson:"amountCents"`
+}
+
+// FinalFare is what the rider pays once the trip is completed.
+type FinalFare struct {
+	QuotedCents int64            `bson:"quotedCents"`
+	Adjustments []FareAdjustment `bson:"adjustments"`
+	TotalCents  int64            `bson:"totalCents"`
+}
+
+func NewFinalFare(quotedCents int64, adjustments []FareAdjustment) *FinalFare {
+	total := quotedCents
+	for _, a := range adjustments {
+		total += a.AmountCents
+	}
+
+	return &FinalFare{
+		QuotedCents: quotedCents,
+		Adjustments: adjustments,
+		TotalCents:  max(total, 0),
+	}
+}
+
+func (f *FinalFare) ToProto() *pb.FinalFare {
+	adjustments := make([]*pb.FareAdjustment, len(f.Adjustments))
+	for i, a := range f.Adjustments {
+		adjustments[i] = &pb.FareAdjustment{
+			Reason:        a.Reason,
+			AmountInCents: float64(a.AmountCents),
+		}
+	}
+
+	return &pb.FinalFare{
+		QuotedInCents: float64(f.QuotedCents),
+		Adjustments:   adjustments,
+		TotalInCents:  float64(f.TotalCents),
+	}
+}
+
+// SetOff records that the assigned driver is on their way to the pickup.
+func (t *TripModel) SetOff(driverID string, at time.Time) error {
+	if t.Driver == nil || t.Driver.Id != driverID {
+		return ErrTripDriverMismatch
+	}
+
+	return t.Transition(TripStatusEnRoute, at)
+}
+
+// Start picks the rider up. A driver already waiting at the pickup skips en_route.
+func (t *TripModel) Start(driverID string, at time.Time) error {
+	if t.Driver == nil || t.Driver.Id != driverID {
+		return ErrTripDriverMismatch
+	}
+
+	if t.Status == TripStatusAccepted {
+		if err := t.Transition(TripStatusEnRoute, at); err != nil {
+			return err
+		}
+	}
+
+	if err := t.Transition(TripStatusInProgress, at); err != nil {
+		return err
+	}
+
+	t.PickedUpAt = &at
+
+	return nil
+}
+
+// Complete drops the rider off, distanceMeters is how far the ride actually went.
+// The final fare is settled by the caller, it depends on the pricing.
+func (t *TripModel) Complete(driverID string, distanceMeters float64, at time.Time) error {
+	if t.Driver == nil || t.Driver.Id != driverID {
+		return ErrTripDriverMismatch
+	}
+
+	if err := t.Transition(TripStatusCompleted, at); err != nil {
+		return err
+	}
+
+	t.DroppedOffAt = &at
+	t.ActualDistanceMeters = distanceMeters
+
+	return nil
+}
+
+// RideDuration is the time between pickup and drop off, zero until the trip is completed.
+func (t *TripModel) RideDuration() time.Duration {
+	if t.PickedUpAt == nil || t.DroppedOffAt == nil {
+		return 0
+	}
+
+	return t.DroppedOffAt.Sub(*t.PickedUpAt)
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	pbd "ride-sharing/shared/proto/driver"
+	pb "ride-sharing/shared/proto/trip"
+)
+
+var (
+	ErrTripNotOffered = errors.New("trip is not offered to the driver")
+	// ErrOfferNotRecorded means a driver answered before we saw the offer, the answer can be retried
+	ErrOfferNotRecorded = errors.New("trip offer not recorded yet")
+	// ErrAlreadyAccepted means the driver's accept was already applied
+	ErrAlreadyAccepted = errors.New("trip already accepted by the driver")
+)
+
+// Offer records that the dispatcher offered the trip to the driver. The trip stays
+// offered when the next driver is asked after a timeout, only the latest driver can answer.
+func (t *TripModel) Offer(driverID string, at time.Time) error {
+	if driverID == "" {
+		return fmt.Errorf("driver is required")
+	}
+
+	if t.Status == TripStatusDriverOffered {
+		t.OfferedDriverID = driverID
+		t.UpdatedAt = at
+		return nil
+	}
+
+	if err := t.Transition(TripStatusDriverOffered, at); err != nil {
+		return err
+	}
+
+	t.OfferedDriverID = driverID
+
+	return nil
+}
+
+// Accept assigns the trip to the driver it is offered to.
+func (t *TripModel) Accept(driver *pbd.Driver, at time.Time) error {
+	if t.Status == TripStatusAccepted && t.Driver.GetId() == driver.Id {
+		return ErrAlreadyAccepted
+	}
+
+	if err := t.checkOffer(driver.Id, TripStatusAccepted); err != nil {
+		return err
+	}
+
+	if err := t.Transition(TripStatusAccepted, at); err != nil {
+		return err
+	}
+
+	t.OfferedDriverID = ""
+	t.Driver = &pb.TripDriver{
+		Id:             driver.Id,
+		Name:           driver.Name,
+		ProfilePicture: driver.ProfilePicture,
+		CarPlate:       driver.CarPlate,
+	}
+
+	return nil
+}
+
+// Decline puts the trip back up for dispatch after the driver it is offered to turned it down.
+func (t *TripModel) Decline(driverID string, at time.Time) error {
+	if err := t.checkOffer(driverID, TripStatusPending); err != nil {
+		return err
+	}
+
+	if err := t.Transition(TripStatusPending, at); err != nil {
+		return err
+	}
+
+	t.OfferedDriverID = ""
+
+	return nil
+}
+
+// checkOffer makes sure the driver answering, to move the trip to next, is the one it is offered to.
+func (t *TripModel) checkOffer(driverID string, next TripStatus) error {
+	if t.Status == TripStatusPending {
+		return fmt.Errorf("trip %s: %w", t.ID.Hex(), ErrOfferNotRecorded)
+	}
+
+	if t.Status != TripStatusDriverOffered {
+		return &TransitionError{TripID: t.ID.Hex(), From: t.Status, To: next}
+	}
+
+	if t.OfferedDriverID != driverID {
+		return fmt.Errorf("%w: trip %s is offered to %s, not %s", ErrTripNotOffered, t.ID.Hex(), t.OfferedDriverID, driverID)
+	}
+
+	return nil
+}
@@ -1,18 +1,48 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	tripTypes "ride-sharing/services/trip-service/pkg/types"
 	pb "ride-sharing/shared/proto/trip"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var (
+	ErrRideFareNotFound      = errors.New("ride fare not found")
+	ErrRideFareExpired       = errors.New("ride fare expired")
+	ErrRideFareConsumed      = errors.New("ride fare already used")
+	ErrRideFareOwnerMismatch = errors.New("ride fare does not belong to the user")
+)
+
+// DefaultRideFareTTL is how long a rider can book a previewed fare.
+const DefaultRideFareTTL = 10 * time.Minute
+
 type RideFareModel struct {
-	ID              primitive.ObjectID
-	UserID          string
-	PackageSlug     string
-	TotalPriceCents float64
-	Route           *tripTypes.OsrmApiResponse
+	ID              primitive.ObjectID         `bson:"_id"`
+	UserID          string                     `bson:"userID"`
+	PackageSlug     string                     `bson:"packageSlug"`
+	TotalPriceCents int64                      `bson:"totalPriceInCents"`
+	SurgeMultiplier float64                    `bson:"surgeMultiplier"`
+	Route           *tripTypes.OsrmApiResponse `bson:"route"`
+	ExpiresAt       time.Time                  `bson:"expiresAt"`
+	// ConsumedAt is set once a trip was created from the fare
+	ConsumedAt *time.Time `bson:"consumedAt"`
+}
+
+// Usable tells whether a trip can still be created from the fare at the given time.
+func (r *RideFareModel) Usable(at time.Time) error {
+	if r.ConsumedAt != nil {
+		return fmt.Errorf("%w: %s", ErrRideFareConsumed, r.ID.Hex())
+	}
+
+	if !at.Before(r.ExpiresAt) {
+		return fmt.Errorf("%w: %s", ErrRideFareExpired, r.ID.Hex())
+	}
+
+	return nil
 }
 
 func (r *RideFareModel) ToProto() *pb.RideFare {
@@ -20,7 +50,9 @@ func (r *RideFareModel) ToProto() *pb.RideFare {
 		Id:                r.ID.Hex(),
 		UserID:            r.UserID,
 		PackageSlug:       r.PackageSlug,
-		TotalPriceInCents: r.TotalPriceCents,
+		TotalPriceInCents: float64(r.TotalPriceCents),
+		SurgeMultiplier:   r.SurgeMultiplier,
+		ExpiresAt:         r.ExpiresAt.UTC().Format(time.RFC3339),
 	}
 }
 
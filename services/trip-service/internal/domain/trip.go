@@ -3,44 +3,250 @@ package domain
 import (
 	"context"
 	"ride-sharing/shared/types"
+	"time"
 
 	tripTypes "ride-sharing/services/trip-service/pkg/types"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/proto"
 
+	pbd "ride-sharing/shared/proto/driver"
 	pb "ride-sharing/shared/proto/trip"
 )
 
 type TripModel struct {
-	ID       primitive.ObjectID
-	UserID   string
-	Status   string
-	RideFare *RideFareModel
-	Driver   *pb.TripDriver
+	ID            primitive.ObjectID `bson:"_id"`
+	UserID        string             `bson:"userID"`
+	Status        TripStatus         `bson:"status"`
+	StatusHistory []TripStatusChange `bson:"statusHistory"`
+	RideFare      *RideFareModel     `bson:"rideFare"`
+	Driver        *pb.TripDriver     `bson:"driver"`
+	// OfferedDriverID is the driver the trip is offered to, only they can accept or decline it
+	OfferedDriverID string            `bson:"offeredDriverID"`
+	Cancellation    *TripCancellation `bson:"cancellation"`
+	PickedUpAt      *time.Time        `bson:"pickedUpAt"`
+	DroppedOffAt    *time.Time        `bson:"droppedOffAt"`
+	// ActualDistanceMeters is reported by the driver when completing the trip
+	ActualDistanceMeters float64    `bson:"actualDistanceMeters"`
+	FinalFare            *FinalFare `bson:"finalFare"`
+	CreatedAt            time.Time  `bson:"createdAt"`
+	UpdatedAt            time.Time  `bson:"updatedAt"`
+	// Version is bumped on every update, UpdateTrip rejects stale copies
+	Version int64 `bson:"version"`
+}
+
+// TripStatusChange records when a trip entered a status.
+type TripStatusChange struct {
+	Status TripStatus `bson:"status"`
+	At     time.Time  `bson:"at"`
+}
+
+// Transition moves the trip to the next status, recording when it happened.
+func (t *TripModel) Transition(next TripStatus, at time.Time) error {
+	if _, err := ParseTripStatus(string(next)); err != nil {
+		return err
+	}
+
+	if !t.Status.CanTransitionTo(next) {
+		return &TransitionError{
+			TripID: t.ID.Hex(),
+			From:   t.Status,
+			To:     next,
+		}
+	}
+
+	t.Status = next
+	t.StatusHistory = append(t.StatusHistory, TripStatusChange{Status: next, At: at})
+	t.UpdatedAt = at
+
+	return nil
+}
+
+// StatusChangedAt returns when the trip last entered the given status.
+func (t *TripModel) StatusChangedAt(status TripStatus) (time.Time, bool) {
+	for i := len(t.StatusHistory) - 1; i >= 0; i-- {
+		if t.StatusHistory[i].Status == status {
+			return t.StatusHistory[i].At, true
+		}
+	}
+
+	return time.Time{}, false
 }
 
 func (t *TripModel) ToProto() *pb.Trip {
-	return &pb.Trip{
+	trip := &pb.Trip{
 		Id:           t.ID.Hex(),
 		UserID:       t.UserID,
-		Status:       t.Status,
+		Status:       t.Status.String(),
 		SelectedFare: t.RideFare.ToProto(),
 		Driver:       t.Driver,
 		Route:        t.RideFare.Route.ToProto(),
+		CreatedAt:    t.CreatedAt.UTC().Format(time.RFC3339),
+	}
+
+	if t.Cancellation != nil {
+		trip.Cancellation = t.Cancellation.ToProto()
+	}
+
+	if t.PickedUpAt != nil {
+		trip.PickedUpAt = t.PickedUpAt.UTC().Format(time.RFC3339)
+	}
+
+	if t.DroppedOffAt != nil {
+		trip.DroppedOffAt = t.DroppedOffAt.UTC().Format(time.RFC3339)
+		trip.ActualDistance = t.ActualDistanceMeters
+	}
+
+	if t.FinalFare != nil {
+		trip.FinalFare = t.FinalFare.ToProto()
+	}
+
+	return trip
+}
+
+// Clone returns a copy of the trip that can be changed without touching the original.
+func (t *TripModel) Clone() *TripModel {
+	c := *t
+	c.StatusHistory = append([]TripStatusChange(nil), t.StatusHistory...)
+
+	if t.RideFare != nil {
+		fare := *t.RideFare
+		c.RideFare = &fare
+	}
+
+	if t.Cancellation != nil {
+		cancellation := *t.Cancellation
+		c.Cancellation = &cancellation
+	}
+
+	if t.PickedUpAt != nil {
+		pickedUpAt := *t.PickedUpAt
+		c.PickedUpAt = &pickedUpAt
+	}
+
+	if t.DroppedOffAt != nil {
+		droppedOffAt := *t.DroppedOffAt
+		c.DroppedOffAt = &droppedOffAt
 	}
+
+	if t.FinalFare != nil {
+		finalFare := *t.FinalFare
+		finalFare.Adjustments = append([]FareAdjustment(nil), t.FinalFare.Adjustments...)
+		c.FinalFare = &finalFare
+	}
+
+	if t.Driver != nil {
+		c.Driver = proto.Clone(t.Driver).(*pb.TripDriver)
+	}
+
+	return &c
+}
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// PageRequest asks for the trips after Cursor, newest first.
+type PageRequest struct {
+	Limit int
+	// Cursor is the NextCursor of the previous page, empty for the first page
+	Cursor string
+}
+
+// Size returns the limit clamped to the allowed range.
+func (p PageRequest) Size() int {
+	if p.Limit <= 0 {
+		return DefaultPageSize
+	}
+
+	if p.Limit > MaxPageSize {
+		return MaxPageSize
+	}
+
+	return p.Limit
+}
+
+// TripFilter narrows a trip listing, empty fields match every trip.
+type TripFilter struct {
+	UserID   string
+	DriverID string
+	// Statuses matches trips in any of them
+	Statuses []TripStatus
+}
+
+func (f TripFilter) Matches(t *TripModel) bool {
+	if f.UserID != "" && t.UserID != f.UserID {
+		return false
+	}
+
+	if f.DriverID != "" && t.Driver.GetId() != f.DriverID {
+		return false
+	}
+
+	if len(f.Statuses) == 0 {
+		return true
+	}
+
+	for _, status := range f.Statuses {
+		if t.Status == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+type TripPage struct {
+	Trips []*TripModel
+	// NextCursor is empty on the last page
+	NextCursor string
 }
 
 type TripRepository interface {
 	CreateTrip(ctx context.Context, trip *TripModel) (*TripModel, error) //return the reference
+	GetTripByID(ctx context.Context, id string) (*TripModel, error)
+	// UpdateTrip saves the trip if nobody updated it since it was read and bumps its Version.
+	UpdateTrip(ctx context.Context, trip *TripModel) error
+	ListTrips(ctx context.Context, filter TripFilter, page PageRequest) (*TripPage, error)
+	// ListTripsByUser and ListTripsByStatus are shorthands for ListTrips
+	ListTripsByUser(ctx context.Context, userID string, page PageRequest) (*TripPage, error)
+	ListTripsByStatus(ctx context.Context, status TripStatus, page PageRequest) (*TripPage, error)
 	SaveRideFare(ctx context.Context, f *RideFareModel) error
 
 	GetRideFareByID(ctx context.Context, id string) (*RideFareModel, error)
+	DeleteRideFare(ctx context.Context, id string) error
+	// ConsumeRideFare marks the fare as used, failing with ErrRideFareConsumed or ErrRideFareExpired
+	// when it can't be used anymore. Only one caller can consume a fare.
+	ConsumeRideFare(ctx context.Context, id string, at time.Time) (*RideFareModel, error)
+	// ReleaseRideFare makes a consumed fare bookable again, when its trip couldn't be created.
+	ReleaseRideFare(ctx context.Context, id string) error
+	// DeleteExpiredRideFares removes the fares that expired before the given time and returns how many.
+	DeleteExpiredRideFares(ctx context.Context, before time.Time) (int64, error)
+}
+
+// RouteProvider computes the driving route between two points.
+type RouteProvider interface {
+	GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error)
 }
 
 type TripService interface {
 	CreateTrip(ctx context.Context, fare *RideFareModel) (*TripModel, error)
+	AbortTrip(ctx context.Context, tripID string) error
+	GetTripByID(ctx context.Context, tripID string) (*TripModel, error)
+	TransitionTrip(ctx context.Context, tripID string, next TripStatus) (*TripModel, error)
+	AssignDriver(ctx context.Context, tripID, riderID string, driver *pbd.Driver) (*TripModel, error)
+	OfferTrip(ctx context.Context, tripID, driverID string) (*TripModel, error)
+	DeclineTrip(ctx context.Context, tripID, riderID, driverID string) (*TripModel, error)
+	CancelTrip(ctx context.Context, tripID string, req CancellationRequest) (*TripModel, error)
+	NoDriverFound(ctx context.Context, tripID string) (*TripModel, error)
+	SetOffForPickup(ctx context.Context, tripID, driverID string) (*TripModel, error)
+	StartTrip(ctx context.Context, tripID, driverID string) (*TripModel, error)
+	CompleteTrip(ctx context.Context, tripID, driverID string, distanceMeters float64) (*TripModel, error)
+	ListRiderTrips(ctx context.Context, riderID string, statuses []TripStatus, page PageRequest) (*TripPage, error)
+	ListDriverTrips(ctx context.Context, driverID string, statuses []TripStatus, page PageRequest) (*TripPage, error)
 	GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error)
-	EstimatePackagesPriceWithRoute(route *tripTypes.OsrmApiResponse) []*RideFareModel
+	EstimatePackagesPriceWithRoute(route *tripTypes.OsrmApiResponse, pickup *types.Coordinate) ([]*RideFareModel, error)
 	GenerateTripFares(ctx context.Context, fares []*RideFareModel, userID string, route *tripTypes.OsrmApiResponse) ([]*RideFareModel, error)
 
 	GetAndValidateFare(ctx context.Context, fareID, userID string) (*RideFareModel, error)
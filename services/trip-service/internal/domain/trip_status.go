@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TripStatus is the lifecycle state of a trip.
+type TripStatus string
+
+const (
+	TripStatusPending       TripStatus = "pending"
+	TripStatusDriverOffered TripStatus = "driver_offered"
+	TripStatusAccepted      TripStatus = "accepted"
+	TripStatusEnRoute       TripStatus = "en_route"
+	TripStatusInProgress    TripStatus = "in_progress"
+	TripStatusCompleted     TripStatus = "completed"
+	TripStatusCancelled     TripStatus = "cancelled"
+	TripStatusNoDriver      TripStatus = "no_driver"
+)
+
+var (
+	ErrTripNotFound          = errors.New("trip not found")
+	ErrUnknownTripStatus     = errors.New("unknown trip status")
+	ErrInvalidTripTransition = errors.New("invalid trip status transition")
+	ErrTripOwnerMismatch     = errors.New("trip does not belong to the rider")
+	ErrTripDriverMismatch    = errors.New("trip is not assigned to the driver")
+	ErrTripVersionConflict   = errors.New("trip was modified concurrently")
+	ErrInvalidCursor         = errors.New("invalid page cursor")
+	ErrNoRoute               = errors.New("no route found")
+)
+
+// tripTransitions lists, for every status, the statuses a trip may move to next.
+// A trip is only accepted by the driver it was offered to, see TripModel.Accept.
+var tripTransitions = map[TripStatus][]TripStatus{
+	TripStatusPending: {
+		TripStatusDriverOffered,
+		TripStatusCancelled,
+		TripStatusNoDriver,
+	},
+	TripStatusDriverOffered: {
+		TripStatusPending, // driver declined or the offer expired
+		TripStatusAccepted,
+		TripStatusCancelled,
+		TripStatusNoDriver,
+	},
+	TripStatusAccepted: {
+		TripStatusEnRoute,
+		TripStatusCancelled,
+	},
+	TripStatusEnRoute: {
+		TripStatusInProgress,
+		TripStatusCancelled,
+	},
+	TripStatusInProgress: {
+		TripStatusCompleted,
+		TripStatusCancelled,
+	},
+	TripStatusCompleted: {},
+	TripStatusCancelled: {},
+	TripStatusNoDriver:  {},
+}
+
+// TransitionError is returned when a trip is asked to move to a status that is
+// not reachable from its current one.
+type TransitionError struct {
+	TripID string
+	From   TripStatus
+	To     TripStatus
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("trip %s: cannot move from %q to %q", e.TripID, e.From, e.To)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrInvalidTripTransition
+}
+
+// ParseTripStatus validates a raw status string.
+func ParseTripStatus(s string) (TripStatus, error) {
+	status := TripStatus(s)
+	if _, ok := tripTransitions[status]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownTripStatus, s)
+	}
+
+	return status, nil
+}
+
+func (s TripStatus) String() string {
+	return string(s)
+}
+
+// IsTerminal reports whether no further transitions are possible.
+func (s TripStatus) IsTerminal() bool {
+	next, ok := tripTransitions[s]
+	return ok && len(next) == 0
+}
+
+// IsAwaitingDriver reports whether the trip is still looking for a driver.
+func (s TripStatus) IsAwaitingDriver() bool {
+	return s == TripStatusPending || s == TripStatusDriverOffered
+}
+
+// CanTransitionTo reports whether moving from s to next is allowed.
+func (s TripStatus) CanTransitionTo(next TripStatus) bool {
+	for _, allowed := range tripTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
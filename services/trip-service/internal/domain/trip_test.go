@@ -0,0 +1,275 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pbd "ride-sharing/shared/proto/driver"
+	pb "ride-sharing/shared/proto/trip"
+)
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    TripStatus
+		to      TripStatus
+		wantErr error
+	}{
+		{name: "pending to offered", from: TripStatusPending, to: TripStatusDriverOffered},
+		{name: "pending can't be accepted", from: TripStatusPending, to: TripStatusAccepted, wantErr: ErrInvalidTripTransition},
+		{name: "pending to no driver", from: TripStatusPending, to: TripStatusNoDriver},
+		{name: "offer declined", from: TripStatusDriverOffered, to: TripStatusPending},
+		{name: "offer accepted", from: TripStatusDriverOffered, to: TripStatusAccepted},
+		{name: "accepted to en route", from: TripStatusAccepted, to: TripStatusEnRoute},
+		{name: "en route to in progress", from: TripStatusEnRoute, to: TripStatusInProgress},
+		{name: "in progress to completed", from: TripStatusInProgress, to: TripStatusCompleted},
+		{name: "in progress to cancelled", from: TripStatusInProgress, to: TripStatusCancelled},
+		{name: "pending can't start", from: TripStatusPending, to: TripStatusInProgress, wantErr: ErrInvalidTripTransition},
+		{name: "accepted can't go back", from: TripStatusAccepted, to: TripStatusPending, wantErr: ErrInvalidTripTransition},
+		{name: "offered again", from: TripStatusDriverOffered, to: TripStatusDriverOffered, wantErr: ErrInvalidTripTransition},
+		{name: "completed is terminal", from: TripStatusCompleted, to: TripStatusCancelled, wantErr: ErrInvalidTripTransition},
+		{name: "cancelled is terminal", from: TripStatusCancelled, to: TripStatusPending, wantErr: ErrInvalidTripTransition},
+		{name: "no driver is terminal", from: TripStatusNoDriver, to: TripStatusAccepted, wantErr: ErrInvalidTripTransition},
+		{name: "unknown status", from: TripStatusPending, to: "teleported", wantErr: ErrUnknownTripStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := time.Now()
+			at := created.Add(time.Minute)
+
+			trip := &TripModel{
+				Status:        tt.from,
+				StatusHistory: []TripStatusChange{{Status: tt.from, At: created}},
+				UpdatedAt:     created,
+			}
+
+			err := trip.Transition(tt.to, at)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Transition error = %v, want %v", err, tt.wantErr)
+				}
+
+				if trip.Status != tt.from || len(trip.StatusHistory) != 1 || !trip.UpdatedAt.Equal(created) {
+					t.Fatalf("failed transition changed the trip: %+v", trip)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Transition: %v", err)
+			}
+
+			if trip.Status != tt.to {
+				t.Fatalf("status = %s, want %s", trip.Status, tt.to)
+			}
+
+			if got, ok := trip.StatusChangedAt(tt.to); !ok || !got.Equal(at) {
+				t.Fatalf("StatusChangedAt(%s) = %v, %v, want %v", tt.to, got, ok, at)
+			}
+
+			if !trip.UpdatedAt.Equal(at) {
+				t.Fatalf("UpdatedAt = %v, want %v", trip.UpdatedAt, at)
+			}
+		})
+	}
+}
+
+func TestTransitionError(t *testing.T) {
+	trip := &TripModel{Status: TripStatusCompleted}
+
+	err := trip.Transition(TripStatusPending, time.Now())
+
+	var transitionErr *TransitionError
+	if !errors.As(err, &transitionErr) {
+		t.Fatalf("Transition error = %T, want *TransitionError", err)
+	}
+
+	if transitionErr.From != TripStatusCompleted || transitionErr.To != TripStatusPending {
+		t.Fatalf("TransitionError = %+v", transitionErr)
+	}
+}
+
+func TestTripStatus(t *testing.T) {
+	tests := []struct {
+		status         TripStatus
+		terminal       bool
+		awaitingDriver bool
+		invalid        bool
+	}{
+		{status: TripStatusPending, awaitingDriver: true},
+		{status: TripStatusDriverOffered, awaitingDriver: true},
+		{status: TripStatusAccepted},
+		{status: TripStatusEnRoute},
+		{status: TripStatusInProgress},
+		{status: TripStatusCompleted, terminal: true},
+		{status: TripStatusCancelled, terminal: true},
+		{status: TripStatusNoDriver, terminal: true},
+		{status: "lost", invalid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if _, err := ParseTripStatus(string(tt.status)); (err != nil) != tt.invalid {
+				t.Fatalf("ParseTripStatus error = %v", err)
+			}
+
+			if got := tt.status.IsTerminal(); got != tt.terminal {
+				t.Fatalf("IsTerminal = %v, want %v", got, tt.terminal)
+			}
+
+			if got := tt.status.IsAwaitingDriver(); got != tt.awaitingDriver {
+				t.Fatalf("IsAwaitingDriver = %v, want %v", got, tt.awaitingDriver)
+			}
+		})
+	}
+}
+
+func TestOfferAnswers(t *testing.T) {
+	offeredTo := func(driverID string) *TripModel {
+		return &TripModel{Status: TripStatusDriverOffered, OfferedDriverID: driverID}
+	}
+
+	tests := []struct {
+		name       string
+		trip       *TripModel
+		answer     func(*TripModel, time.Time) error
+		wantErr    error
+		wantStatus TripStatus
+	}{
+		{
+			name:       "offered driver accepts",
+			trip:       offeredTo("d1"),
+			answer:     accept("d1"),
+			wantStatus: TripStatusAccepted,
+		},
+		{
+			name:       "other driver accepts",
+			trip:       offeredTo("d2"),
+			answer:     accept("d1"),
+			wantErr:    ErrTripNotOffered,
+			wantStatus: TripStatusDriverOffered,
+		},
+		{
+			name:       "accept before the offer is recorded",
+			trip:       &TripModel{Status: TripStatusPending},
+			answer:     accept("d1"),
+			wantErr:    ErrOfferNotRecorded,
+			wantStatus: TripStatusPending,
+		},
+		{
+			name:       "same accept again",
+			trip:       &TripModel{Status: TripStatusAccepted, Driver: &pb.TripDriver{Id: "d1"}},
+			answer:     accept("d1"),
+			wantErr:    ErrAlreadyAccepted,
+			wantStatus: TripStatusAccepted,
+		},
+		{
+			name:       "accept of a trip someone else took",
+			trip:       &TripModel{Status: TripStatusAccepted, Driver: &pb.TripDriver{Id: "d2"}},
+			answer:     accept("d1"),
+			wantErr:    ErrInvalidTripTransition,
+			wantStatus: TripStatusAccepted,
+		},
+		{
+			name:       "offered driver declines",
+			trip:       offeredTo("d1"),
+			answer:     decline("d1"),
+			wantStatus: TripStatusPending,
+		},
+		{
+			name:       "other driver declines",
+			trip:       offeredTo("d2"),
+			answer:     decline("d1"),
+			wantErr:    ErrTripNotOffered,
+			wantStatus: TripStatusDriverOffered,
+		},
+		{
+			name: "offered to the next driver",
+			trip: offeredTo("d1"),
+			answer: func(trip *TripModel, at time.Time) error {
+				if err := trip.Offer("d2", at); err != nil {
+					return err
+				}
+
+				return trip.Accept(&pbd.Driver{Id: "d1"}, at)
+			},
+			wantErr:    ErrTripNotOffered,
+			wantStatus: TripStatusDriverOffered,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.answer(tt.trip, time.Now())
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.trip.Status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s", tt.trip.Status, tt.wantStatus)
+			}
+
+			if tt.wantErr == nil && tt.trip.OfferedDriverID != "" {
+				t.Fatalf("OfferedDriverID = %q after the answer, want it cleared", tt.trip.OfferedDriverID)
+			}
+		})
+	}
+}
+
+func accept(driverID string) func(*TripModel, time.Time) error {
+	return func(trip *TripModel, at time.Time) error {
+		return trip.Accept(&pbd.Driver{Id: driverID}, at)
+	}
+}
+
+func decline(driverID string) func(*TripModel, time.Time) error {
+	return func(trip *TripModel, at time.Time) error {
+		return trip.Decline(driverID, at)
+	}
+}
+
+func TestSetOff(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   TripStatus
+		driverID string
+		wantErr  error
+	}{
+		{name: "assigned driver", status: TripStatusAccepted, driverID: "d1"},
+		{name: "another driver", status: TripStatusAccepted, driverID: "d2", wantErr: ErrTripDriverMismatch},
+		{name: "already on the way", status: TripStatusEnRoute, driverID: "d1", wantErr: ErrInvalidTripTransition},
+		{name: "already picked up", status: TripStatusInProgress, driverID: "d1", wantErr: ErrInvalidTripTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trip := &TripModel{Status: tt.status, Driver: &pb.TripDriver{Id: "d1"}}
+
+			err := trip.SetOff(tt.driverID, time.Now())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SetOff error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("SetOff: %v", err)
+			}
+
+			// the trip waits en route until the driver picks the rider up
+			if trip.Status != TripStatusEnRoute {
+				t.Fatalf("status = %s, want %s", trip.Status, TripStatusEnRoute)
+			}
+
+			if err := trip.Start("d1", time.Now()); err != nil || trip.Status != TripStatusInProgress {
+				t.Fatalf("Start after setting off = %v, status %s", err, trip.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"ride-sharing/services/trip-service/internal/domain"
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+type driverConsumer struct {
+	rabbitmq  *messaging.RabbitMQ
+	service   domain.TripService
+	publisher *TripEventPublisher
+}
+
+func NewDriverConsumer(rabbitmq *messaging.RabbitMQ, service domain.TripService, publisher *TripEventPublisher) *driverConsumer {
+	return &driverConsumer{
+		rabbitmq:  rabbitmq,
+		service:   service,
+		publisher: publisher,
+	}
+}
+
+func (c *driverConsumer) Listen(ctx context.Context, opts messaging.ConsumerOptions) error {
+	return c.rabbitmq.ConsumeMessages(ctx, messaging.DriverTripResponseQueue, opts, func(ctx context.Context, msg amqp091.Delivery) error {
+		switch msg.RoutingKey {
+		case contracts.DriverCmdTripRequest, contracts.TripEventNoDriversFound:
+			event, err := messaging.DecodeEvent[messaging.TripEventData](msg)
+			if err != nil {
+				log.Printf("Failed to unmarshal message: %v", err)
+				return messaging.Poison(err)
+			}
+
+			if msg.RoutingKey == contracts.TripEventNoDriversFound {
+				return c.handleNoDriversFound(ctx, event.Payload)
+			}
+
+			// the command is addressed to the driver it offers the trip to
+			return c.handleTripOffered(ctx, event.OwnerID, event.Payload)
+		}
+
+		event, err := messaging.DecodeEvent[messaging.DriverTripResponseData](msg)
+		if err != nil {
+			log.Printf("Failed to unmarshal message: %v", err)
+			return messaging.Poison(err)
+		}
+
+		payload := event.Payload
+
+		switch msg.RoutingKey {
+		case contracts.DriverCmdTripAccept:
+			return c.handleTripAccepted(ctx, payload)
+		case contracts.DriverCmdTripDecline:
+			return c.handleTripDeclined(ctx, payload)
+		}
+
+		log.Printf("Unknown driver command: %s", msg.RoutingKey)
+
+		return nil
+	})
+}
+
+func (c *driverConsumer) handleTripAccepted(ctx context.Context, payload messaging.DriverTripResponseData) error {
+	if payload.Driver == nil || payload.Driver.Id == "" {
+		log.Printf("Ignoring trip accept for %s: missing driver", payload.TripID)
+		return nil
+	}
+
+	// accepting again publishes the assignment again, in case the first publish failed
+	trip, err := c.service.AssignDriver(ctx, payload.TripID, payload.RiderID, payload.Driver)
+	if err != nil {
+		if isStaleDriverResponse(err) {
+			// the trip was offered to someone else, taken, cancelled or never existed: nothing to retry
+			log.Printf("Ignoring trip accept for %s: %v", payload.TripID, err)
+			return nil
+		}
+
+		return err
+	}
+
+	log.Printf("Driver %s assigned to trip %s", trip.Driver.Id, payload.TripID)
+
+	return c.publisher.PublishDriverAssigned(ctx, trip)
+}
+
+func (c *driverConsumer) handleTripOffered(ctx context.Context, driverID string, payload messaging.TripEventData) error {
+	if payload.Trip == nil || driverID == "" {
+		log.Printf("Ignoring trip offer: missing trip or driver")
+		return nil
+	}
+
+	if _, err := c.service.OfferTrip(ctx, payload.Trip.Id, driverID); err != nil {
+		if isStaleDriverResponse(err) {
+			// answered or cancelled before we got the offer
+			log.Printf("Ignoring trip offer for %s: %v", payload.Trip.Id, err)
+			return nil
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *driverConsumer) handleNoDriversFound(ctx context.Context, payload messaging.TripEventData) error {
+	if payload.Trip == nil {
+		return nil
+	}
+
+	if _, err := c.service.NoDriverFound(ctx, payload.Trip.Id); err != nil {
+		if isStaleDriverResponse(err) {
+			// a driver got it or the rider cancelled in the meantime
+			log.Printf("Ignoring no drivers found for %s: %v", payload.Trip.Id, err)
+			return nil
+		}
+
+		return err
+	}
+
+	log.Printf("No driver found for trip %s", payload.Trip.Id)
+
+	return nil
+}
+
+func (c *driverConsumer) handleTripDeclined(ctx context.Context, payload messaging.DriverTripResponseData) error {
+	if payload.Driver == nil || payload.Driver.Id == "" {
+		log.Printf("Ignoring trip decline for %s: missing driver", payload.TripID)
+		return nil
+	}
+
+	trip, err := c.service.DeclineTrip(ctx, payload.TripID, payload.RiderID, payload.Driver.Id)
+	if err != nil {
+		if isStaleDriverResponse(err) {
+			log.Printf("Ignoring trip decline for %s: %v", payload.TripID, err)
+			return nil
+		}
+
+		return err
+	}
+
+	// let the driver service look for someone else
+	return c.publisher.PublishDriverNotInterested(ctx, trip)
+}
+
+func isStaleDriverResponse(err error) bool {
+	return errors.Is(err, domain.ErrTripNotFound) ||
+		errors.Is(err, domain.ErrInvalidTripTransition) ||
+		errors.Is(err, domain.ErrTripOwnerMismatch) ||
+		errors.Is(err, domain.ErrTripNotOffered)
+}
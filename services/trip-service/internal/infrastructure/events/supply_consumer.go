@@ -0,0 +1,50 @@
+package events
+
+import (
+	"context"
+	"log"
+	"ride-sharing/services/trip-service/internal/surge"
+	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/messaging"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// supplyConsumer feeds the driver supply snapshots into the surge tracker.
+type supplyConsumer struct {
+	rabbitmq *messaging.RabbitMQ
+	tracker  *surge.Tracker
+}
+
+func NewSupplyConsumer(rabbitmq *messaging.RabbitMQ, tracker *surge.Tracker) *supplyConsumer {
+	return &supplyConsumer{
+		rabbitmq: rabbitmq,
+		tracker:  tracker,
+	}
+}
+
+func (c *supplyConsumer) Listen(ctx context.Context) error {
+	// every trip service instance needs every snapshot, so each one gets its own queue
+	return c.rabbitmq.ConsumeExclusive(ctx, []string{contracts.DriverEventSupply}, messaging.DefaultConsumerOptions(), func(ctx context.Context, msg amqp091.Delivery) error {
+		event, err := messaging.DecodeEvent[messaging.DriverSupplyData](msg)
+		if err != nil {
+			log.Printf("Failed to unmarshal message: %v", err)
+			return messaging.Poison(err)
+		}
+
+		payload := event.Payload
+
+		cells := make([]surge.CellSupply, len(payload.Cells))
+		for i, cell := range payload.Cells {
+			cells[i] = surge.CellSupply{
+				Geohash:     cell.Geohash,
+				PackageSlug: cell.PackageSlug,
+				Drivers:     cell.Drivers,
+			}
+		}
+
+		c.tracker.UpdateSupply(payload.Precision, cells, payload.At)
+
+		return nil
+	})
+}
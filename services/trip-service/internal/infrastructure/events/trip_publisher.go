@@ -2,7 +2,6 @@ package events
 
 import (
 	"context"
-	"encoding/json"
 	"ride-sharing/services/trip-service/internal/domain"
 	"ride-sharing/shared/contracts"
 	"ride-sharing/shared/messaging"
@@ -19,16 +18,31 @@ func NewTripEventPublisher(rabbitmq *messaging.RabbitMQ) *TripEventPublisher {
 }
 
 func (p *TripEventPublisher) PublishTripCreated(ctx context.Context, trip *domain.TripModel) error {
-	payload := messaging.TripEventData{
-		Trip: trip.ToProto(),
-	}
+	return p.publishTripEvent(ctx, contracts.TripEventCreated, trip)
+}
 
-	tripEventJson, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-	return p.rabbitmq.PublishMessage(ctx, contracts.TripEventCreated, contracts.AmqpMessage{
-		OwnerID: trip.UserID,
-		Data:    tripEventJson,
+func (p *TripEventPublisher) PublishDriverAssigned(ctx context.Context, trip *domain.TripModel) error {
+	return p.publishTripEvent(ctx, contracts.TripEventDriverAssigned, trip)
+}
+
+func (p *TripEventPublisher) PublishDriverNotInterested(ctx context.Context, trip *domain.TripModel) error {
+	return p.publishTripEvent(ctx, contracts.TripEventDriverNotInterested, trip)
+}
+
+func (p *TripEventPublisher) PublishTripStarted(ctx context.Context, trip *domain.TripModel) error {
+	return p.publishTripEvent(ctx, contracts.TripEventStarted, trip)
+}
+
+func (p *TripEventPublisher) PublishTripCancelled(ctx context.Context, trip *domain.TripModel) error {
+	return p.publishTripEvent(ctx, contracts.TripEventCancelled, trip)
+}
+
+func (p *TripEventPublisher) PublishTripCompleted(ctx context.Context, trip *domain.TripModel) error {
+	return p.publishTripEvent(ctx, contracts.TripEventCompleted, trip)
+}
+
+func (p *TripEventPublisher) publishTripEvent(ctx context.Context, routingKey string, trip *domain.TripModel) error {
+	return messaging.PublishEvent(ctx, p.rabbitmq, routingKey, trip.UserID, messaging.TripEventData{
+		Trip: trip.ToProto(),
 	})
 }
@@ -2,9 +2,12 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"log"
 	"ride-sharing/services/trip-service/internal/domain"
 	"ride-sharing/services/trip-service/internal/infrastructure/events"
+	"ride-sharing/services/trip-service/internal/infrastructure/routing"
+	"ride-sharing/shared/messaging"
 
 	pb "ride-sharing/shared/proto/trip"
 
@@ -47,17 +50,28 @@ func (h *gRPCHandler) PreviewTrip(ctx context.Context, req *pb.PreviewTripReques
 		Longitude: destination.Longitude,
 	}
 
+	if req.GetSkipRouteCache() {
+		ctx = routing.WithoutCache(ctx)
+	}
+
 	route, err := h.service.GetRoute(ctx, pickupCoords, destCoord)
 
 	if err != nil {
 		log.Println(err)
+		if errors.Is(err, domain.ErrNoRoute) {
+			return nil, status.Errorf(codes.NotFound, "no route between pickup and destination")
+		}
 		return nil, status.Errorf(codes.Internal, "failed to get route: %v", err)
 	}
 
 	userId := req.GetUserId()
 
 	// 1. Estimate the ride fares prices based on the route (ex: distance)
-	estimatedFares := h.service.EstimatePackagesPriceWithRoute(route)
+	estimatedFares, err := h.service.EstimatePackagesPriceWithRoute(route, pickupCoords)
+	if err != nil {
+		log.Println(err)
+		return nil, status.Errorf(codes.Internal, "failed to estimate fares: %v", err)
+	}
 
 	// 2. Store the ride fares for the the create the trip to fetch and variables
 	fares, err := h.service.GenerateTripFares(ctx, estimatedFares, userId, route)
@@ -82,23 +96,30 @@ func (h *gRPCHandler) CreateTrip(ctx context.Context, req *pb.CreateTripRequest)
 
 	if err != nil {
 		log.Println(err)
-		return nil, status.Errorf(codes.Internal, "failed to  validate fare: %v", err)
+		return nil, fareError(err, "failed to validate fare")
 	}
 
 	trip, err := h.service.CreateTrip(ctx, rideFare)
 
 	if err != nil {
 		log.Println(err)
-		return nil, status.Errorf(codes.Internal, "failed to create trip: %v", err)
+		return nil, fareError(err, "failed to create trip")
 	}
 
 	if err := h.publisher.PublishTripCreated(ctx, trip); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create the trip created events: %v", err)
+		log.Printf("Failed to publish trip %s created: %v", trip.ID.Hex(), err)
+
+		// nobody is going to dispatch it, don't leave the rider waiting for a driver
+		if abortErr := h.service.AbortTrip(ctx, trip.ID.Hex()); abortErr != nil {
+			log.Printf("Failed to abort undispatched trip %s: %v", trip.ID.Hex(), abortErr)
+		}
+
+		return nil, publishError(err, "failed to publish the trip created event")
 	}
 
 	return &pb.CreateTripResponse{
 		TripID: trip.ID.Hex(),
-		// Trip:   trip.ToProto(),
+		Trip:   trip.ToProto(),
 	}, nil
 
 	// 2. Call creare trip
@@ -108,3 +129,193 @@ func (h *gRPCHandler) CreateTrip(ctx context.Context, req *pb.CreateTripRequest)
 
 	// return nil, status.Errorf(codes.Unimplemented, "method CreateTrip not implemented")
 }
+
+// fareError gives every reason a fare can't be booked its own status, so clients can tell
+// an expired fare (preview again) from a used one.
+func fareError(err error, msg string) error {
+	switch {
+	case errors.Is(err, domain.ErrRideFareNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", msg, err)
+	case errors.Is(err, domain.ErrRideFareExpired):
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", msg, err)
+	case errors.Is(err, domain.ErrRideFareConsumed):
+		return status.Errorf(codes.AlreadyExists, "%s: %v", msg, err)
+	case errors.Is(err, domain.ErrRideFareOwnerMismatch):
+		return status.Errorf(codes.PermissionDenied, "%s: %v", msg, err)
+	}
+
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
+}
+
+func (h *gRPCHandler) CancelTrip(ctx context.Context, req *pb.CancelTripRequest) (*pb.CancelTripResponse, error) {
+	// system cancellations come from the trip service itself, never from a client
+	if domain.CancellationActor(req.GetActor()) == domain.CancelledBySystem {
+		return nil, status.Error(codes.PermissionDenied, "the system actor can't be used by clients")
+	}
+
+	trip, err := h.service.CancelTrip(ctx, req.GetTripID(), domain.CancellationRequest{
+		Actor:   domain.CancellationActor(req.GetActor()),
+		ActorID: req.GetActorID(),
+		Reason:  domain.CancellationReason(req.GetReason()),
+		Note:    req.GetNote(),
+	})
+	if err != nil {
+		log.Println(err)
+		return nil, tripError(err, "failed to cancel trip")
+	}
+
+	// the trip stays cancelled, retrying the same cancellation publishes the event again
+	if err := h.publisher.PublishTripCancelled(ctx, trip); err != nil {
+		return nil, publishError(err, "failed to publish the trip cancelled event")
+	}
+
+	return &pb.CancelTripResponse{
+		Trip: trip.ToProto(),
+	}, nil
+}
+
+func (h *gRPCHandler) SetOffForPickup(ctx context.Context, req *pb.SetOffForPickupRequest) (*pb.SetOffForPickupResponse, error) {
+	trip, err := h.service.SetOffForPickup(ctx, req.GetTripID(), req.GetDriverID())
+	if err != nil {
+		log.Println(err)
+		return nil, tripError(err, "failed to set off for the pickup")
+	}
+
+	return &pb.SetOffForPickupResponse{
+		Trip: trip.ToProto(),
+	}, nil
+}
+
+func (h *gRPCHandler) StartTrip(ctx context.Context, req *pb.StartTripRequest) (*pb.StartTripResponse, error) {
+	trip, err := h.service.StartTrip(ctx, req.GetTripID(), req.GetDriverID())
+	if err != nil {
+		log.Println(err)
+		return nil, tripError(err, "failed to start trip")
+	}
+
+	// retrying the start publishes the event again
+	if err := h.publisher.PublishTripStarted(ctx, trip); err != nil {
+		return nil, publishError(err, "failed to publish the trip started event")
+	}
+
+	return &pb.StartTripResponse{
+		Trip: trip.ToProto(),
+	}, nil
+}
+
+func (h *gRPCHandler) CompleteTrip(ctx context.Context, req *pb.CompleteTripRequest) (*pb.CompleteTripResponse, error) {
+	if req.GetDistanceMeters() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "distance can't be negative")
+	}
+
+	trip, err := h.service.CompleteTrip(ctx, req.GetTripID(), req.GetDriverID(), req.GetDistanceMeters())
+	if err != nil {
+		log.Println(err)
+		return nil, tripError(err, "failed to complete trip")
+	}
+
+	// retrying the completion publishes the event again
+	if err := h.publisher.PublishTripCompleted(ctx, trip); err != nil {
+		return nil, publishError(err, "failed to publish the trip completed event")
+	}
+
+	return &pb.CompleteTripResponse{
+		Trip: trip.ToProto(),
+	}, nil
+}
+
+func (h *gRPCHandler) GetTrip(ctx context.Context, req *pb.GetTripRequest) (*pb.GetTripResponse, error) {
+	if req.GetUserID() == "" {
+		return nil, status.Error(codes.InvalidArgument, "userID is required")
+	}
+
+	trip, err := h.service.GetTripByID(ctx, req.GetTripID())
+	if err != nil {
+		log.Println(err)
+		return nil, tripError(err, "failed to get trip")
+	}
+
+	if userID := req.GetUserID(); userID != trip.UserID && userID != trip.Driver.GetId() {
+		return nil, status.Errorf(codes.PermissionDenied, "trip %s does not belong to %s", req.GetTripID(), userID)
+	}
+
+	return &pb.GetTripResponse{
+		Trip: trip.ToProto(),
+	}, nil
+}
+
+func (h *gRPCHandler) ListRiderTrips(ctx context.Context, req *pb.ListTripsRequest) (*pb.ListTripsResponse, error) {
+	return h.listTrips(ctx, req, h.service.ListRiderTrips)
+}
+
+func (h *gRPCHandler) ListDriverTrips(ctx context.Context, req *pb.ListTripsRequest) (*pb.ListTripsResponse, error) {
+	return h.listTrips(ctx, req, h.service.ListDriverTrips)
+}
+
+type listTripsFunc func(ctx context.Context, userID string, statuses []domain.TripStatus, page domain.PageRequest) (*domain.TripPage, error)
+
+func (h *gRPCHandler) listTrips(ctx context.Context, req *pb.ListTripsRequest, list listTripsFunc) (*pb.ListTripsResponse, error) {
+	if req.GetUserID() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "user id is required")
+	}
+
+	statuses := make([]domain.TripStatus, len(req.GetStatuses()))
+	for i, s := range req.GetStatuses() {
+		parsed, err := domain.ParseTripStatus(s)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		statuses[i] = parsed
+	}
+
+	page, err := list(ctx, req.GetUserID(), statuses, domain.PageRequest{
+		Limit:  int(req.GetLimit()),
+		Cursor: req.GetCursor(),
+	})
+	if err != nil {
+		log.Println(err)
+		if errors.Is(err, domain.ErrInvalidCursor) {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to list trips: %v", err)
+	}
+
+	trips := make([]*pb.Trip, len(page.Trips))
+	for i, t := range page.Trips {
+		trips[i] = t.ToProto()
+	}
+
+	return &pb.ListTripsResponse{
+		Trips:      trips,
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// tripError maps the errors of the trip updates to a status.
+func tripError(err error, msg string) error {
+	switch {
+	case errors.Is(err, domain.ErrTripNotFound):
+		return status.Errorf(codes.NotFound, "%s: %v", msg, err)
+	case errors.Is(err, domain.ErrInvalidCancellation):
+		return status.Errorf(codes.InvalidArgument, "%s: %v", msg, err)
+	case errors.Is(err, domain.ErrTripOwnerMismatch), errors.Is(err, domain.ErrTripDriverMismatch):
+		return status.Errorf(codes.PermissionDenied, "%s: %v", msg, err)
+	case errors.Is(err, domain.ErrInvalidTripTransition), errors.Is(err, domain.ErrCancellationNotAllowed):
+		return status.Errorf(codes.FailedPrecondition, "%s: %v", msg, err)
+	}
+
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
+}
+
+// publishError reports a broker that is away, or a message nobody would get, as unavailable.
+func publishError(err error, msg string) error {
+	switch {
+	case errors.Is(err, messaging.ErrNotConnected),
+		errors.Is(err, messaging.ErrUnroutable),
+		errors.Is(err, messaging.ErrPublishNacked),
+		errors.Is(err, context.DeadlineExceeded):
+		return status.Errorf(codes.Unavailable, "%s: %v", msg, err)
+	}
+
+	return status.Errorf(codes.Internal, "%s: %v", msg, err)
+}
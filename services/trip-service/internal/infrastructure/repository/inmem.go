@@ -1,14 +1,23 @@
 package repository
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"ride-sharing/services/trip-service/internal/domain"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// inmemRepository keeps everything in maps. It hands out copies, like a real
+// database would, so callers have to go through UpdateTrip to change a trip.
 type inmemRepository struct {
 	trips     map[string]*domain.TripModel
 	rideFares map[string]*domain.RideFareModel
+	mu        sync.RWMutex
 }
 
 func NewInmemRepository() *inmemRepository {
@@ -18,23 +27,186 @@ func NewInmemRepository() *inmemRepository {
 	}
 }
 
-// My name is
 func (r *inmemRepository) CreateTrip(ctx context.Context, trip *domain.TripModel) (*domain.TripModel, error) {
-	r.trips[trip.ID.Hex()] = trip
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.trips[trip.ID.Hex()]; exists {
+		return nil, fmt.Errorf("trip already exists with ID: %s", trip.ID.Hex())
+	}
+
+	r.trips[trip.ID.Hex()] = trip.Clone()
 	return trip, nil
 }
 
+func (r *inmemRepository) GetTripByID(ctx context.Context, id string) (*domain.TripModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	trip, exists := r.trips[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTripNotFound, id)
+	}
+
+	return trip.Clone(), nil
+}
+
+func (r *inmemRepository) UpdateTrip(ctx context.Context, trip *domain.TripModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.trips[trip.ID.Hex()]
+	if !exists {
+		return fmt.Errorf("%w: %s", domain.ErrTripNotFound, trip.ID.Hex())
+	}
+
+	if stored.Version != trip.Version {
+		return fmt.Errorf("%w: %s", domain.ErrTripVersionConflict, trip.ID.Hex())
+	}
+
+	trip.Version++
+	r.trips[trip.ID.Hex()] = trip.Clone()
+
+	return nil
+}
+
+// ListTripsByUser pages through the trips of a rider, see ListTrips.
+func (r *inmemRepository) ListTripsByUser(ctx context.Context, userID string, page domain.PageRequest) (*domain.TripPage, error) {
+	return r.ListTrips(ctx, domain.TripFilter{UserID: userID}, page)
+}
+
+// ListTripsByStatus pages through the trips in the status, see ListTrips.
+func (r *inmemRepository) ListTripsByStatus(ctx context.Context, status domain.TripStatus, page domain.PageRequest) (*domain.TripPage, error) {
+	return r.ListTrips(ctx, domain.TripFilter{Statuses: []domain.TripStatus{status}}, page)
+}
+
+// ListTrips pages through the matching trips newest first, ObjectIDs grow with time
+// so the ID of the last trip of a page is the cursor to the next one.
+func (r *inmemRepository) ListTrips(ctx context.Context, filter domain.TripFilter, page domain.PageRequest) (*domain.TripPage, error) {
+	var cursor primitive.ObjectID
+	if page.Cursor != "" {
+		oid, err := primitive.ObjectIDFromHex(page.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidCursor, page.Cursor)
+		}
+		cursor = oid
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matches []*domain.TripModel
+	for _, t := range r.trips {
+		if page.Cursor != "" && bytes.Compare(t.ID[:], cursor[:]) >= 0 {
+			continue
+		}
+
+		if filter.Matches(t) {
+			matches = append(matches, t)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return bytes.Compare(matches[i].ID[:], matches[j].ID[:]) > 0
+	})
+
+	result := &domain.TripPage{}
+	size := page.Size()
+
+	if len(matches) > size {
+		matches = matches[:size]
+		result.NextCursor = matches[size-1].ID.Hex()
+	}
+
+	result.Trips = make([]*domain.TripModel, len(matches))
+	for i, t := range matches {
+		result.Trips[i] = t.Clone()
+	}
+
+	return result, nil
+}
+
 func (r *inmemRepository) SaveRideFare(ctx context.Context, fare *domain.RideFareModel) error {
-	r.rideFares[fare.ID.Hex()] = fare
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *fare
+	r.rideFares[fare.ID.Hex()] = &stored
 
 	return nil
 }
 
 func (r *inmemRepository) GetRideFareByID(ctx context.Context, id string) (*domain.RideFareModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fare, exists := r.rideFares[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRideFareNotFound, id)
+	}
+
+	copied := *fare
+	return &copied, nil
+}
+
+func (r *inmemRepository) DeleteRideFare(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.rideFares[id]; !exists {
+		return fmt.Errorf("%w: %s", domain.ErrRideFareNotFound, id)
+	}
+
+	delete(r.rideFares, id)
+
+	return nil
+}
+
+func (r *inmemRepository) ConsumeRideFare(ctx context.Context, id string, at time.Time) (*domain.RideFareModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	fare, exists := r.rideFares[id]
 	if !exists {
-		return nil, fmt.Errorf("fare does not exist with ID: %s", id)
+		return nil, fmt.Errorf("%w: %s", domain.ErrRideFareNotFound, id)
+	}
+
+	if err := fare.Usable(at); err != nil {
+		return nil, err
+	}
+
+	consumedAt := at
+	fare.ConsumedAt = &consumedAt
+
+	copied := *fare
+	return &copied, nil
+}
+
+func (r *inmemRepository) ReleaseRideFare(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fare, exists := r.rideFares[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", domain.ErrRideFareNotFound, id)
+	}
+
+	fare.ConsumedAt = nil
+
+	return nil
+}
+
+func (r *inmemRepository) DeleteExpiredRideFares(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, fare := range r.rideFares {
+		if fare.ExpiresAt.Before(before) {
+			delete(r.rideFares, id)
+			deleted++
+		}
 	}
 
-	return fare, nil
+	return deleted, nil
 }
@@ -0,0 +1,15 @@
+package repository_test
+
+import (
+	"testing"
+
+	"ride-sharing/services/trip-service/internal/domain"
+	"ride-sharing/services/trip-service/internal/infrastructure/repository"
+	"ride-sharing/services/trip-service/internal/infrastructure/repository/repositorytest"
+)
+
+func TestInmemRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) domain.TripRepository {
+		return repository.NewInmemRepository()
+	})
+}
@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ride-sharing/services/trip-service/internal/domain"
+	"ride-sharing/shared/db"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type mongoRepository struct {
+	db *mongo.Database
+}
+
+func NewMongoRepository(db *mongo.Database) *mongoRepository {
+	return &mongoRepository{
+		db: db,
+	}
+}
+
+func (r *mongoRepository) trips() *mongo.Collection {
+	return r.db.Collection(db.TripsCollection)
+}
+
+func (r *mongoRepository) rideFares() *mongo.Collection {
+	return r.db.Collection(db.RideFaresCollection)
+}
+
+// EnsureIndexes creates the indexes the queries rely on, it is safe to call on every start.
+func (r *mongoRepository) EnsureIndexes(ctx context.Context) error {
+	if _, err := r.trips().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userID", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "driver.id", Value: 1}, {Key: "_id", Value: -1}}},
+	}); err != nil {
+		return fmt.Errorf("failed to create trip indexes: %w", err)
+	}
+
+	if _, err := r.rideFares().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "userID", Value: 1}}},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}},
+	}); err != nil {
+		return fmt.Errorf("failed to create ride fare indexes: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mongoRepository) CreateTrip(ctx context.Context, trip *domain.TripModel) (*domain.TripModel, error) {
+	if _, err := r.trips().InsertOne(ctx, trip); err != nil {
+		return nil, fmt.Errorf("failed to insert trip: %w", err)
+	}
+
+	return trip, nil
+}
+
+func (r *mongoRepository) GetTripByID(ctx context.Context, id string) (*domain.TripModel, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrTripNotFound, id)
+	}
+
+	var trip domain.TripModel
+	if err := r.trips().FindOne(ctx, bson.M{"_id": oid}).Decode(&trip); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrTripNotFound, id)
+		}
+
+		return nil, fmt.Errorf("failed to get trip: %w", err)
+	}
+
+	return &trip, nil
+}
+
+func (r *mongoRepository) UpdateTrip(ctx context.Context, trip *domain.TripModel) error {
+	updated := *trip
+	updated.Version++
+
+	result, err := r.trips().ReplaceOne(ctx, bson.M{"_id": trip.ID, "version": trip.Version}, &updated)
+	if err != nil {
+		return fmt.Errorf("failed to update trip: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		count, err := r.trips().CountDocuments(ctx, bson.M{"_id": trip.ID})
+		if err != nil {
+			return fmt.Errorf("failed to update trip: %w", err)
+		}
+
+		if count == 0 {
+			return fmt.Errorf("%w: %s", domain.ErrTripNotFound, trip.ID.Hex())
+		}
+
+		return fmt.Errorf("%w: %s", domain.ErrTripVersionConflict, trip.ID.Hex())
+	}
+
+	trip.Version = updated.Version
+
+	return nil
+}
+
+// ListTripsByUser pages through the trips of a rider, see ListTrips.
+func (r *mongoRepository) ListTripsByUser(ctx context.Context, userID string, page domain.PageRequest) (*domain.TripPage, error) {
+	return r.ListTrips(ctx, domain.TripFilter{UserID: userID}, page)
+}
+
+// ListTripsByStatus pages through the trips in the status, see ListTrips.
+func (r *mongoRepository) ListTripsByStatus(ctx context.Context, status domain.TripStatus, page domain.PageRequest) (*domain.TripPage, error) {
+	return r.ListTrips(ctx, domain.TripFilter{Statuses: []domain.TripStatus{status}}, page)
+}
+
+// ListTrips pages through the matching trips newest first, using the last _id as cursor.
+func (r *mongoRepository) ListTrips(ctx context.Context, tripFilter domain.TripFilter, page domain.PageRequest) (*domain.TripPage, error) {
+	filter := bson.M{}
+
+	if tripFilter.UserID != "" {
+		filter["userID"] = tripFilter.UserID
+	}
+
+	if tripFilter.DriverID != "" {
+		filter["driver.id"] = tripFilter.DriverID
+	}
+
+	if len(tripFilter.Statuses) > 0 {
+		filter["status"] = bson.M{"$in": tripFilter.Statuses}
+	}
+
+	if page.Cursor != "" {
+		cursor, err := primitive.ObjectIDFromHex(page.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidCursor, page.Cursor)
+		}
+
+		filter["_id"] = bson.M{"$lt": cursor}
+	}
+
+	size := page.Size()
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(size + 1)) // one extra to know whether there is a next page
+
+	cur, err := r.trips().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trips: %w", err)
+	}
+
+	var trips []*domain.TripModel
+	if err := cur.All(ctx, &trips); err != nil {
+		return nil, fmt.Errorf("failed to decode trips: %w", err)
+	}
+
+	result := &domain.TripPage{Trips: trips}
+	if len(trips) > size {
+		result.Trips = trips[:size]
+		result.NextCursor = trips[size-1].ID.Hex()
+	}
+
+	return result, nil
+}
+
+func (r *mongoRepository) SaveRideFare(ctx context.Context, fare *domain.RideFareModel) error {
+	_, err := r.rideFares().ReplaceOne(ctx, bson.M{"_id": fare.ID}, fare, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save ride fare: %w", err)
+	}
+
+	return nil
+}
+
+func (r *mongoRepository) GetRideFareByID(ctx context.Context, id string) (*domain.RideFareModel, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRideFareNotFound, id)
+	}
+
+	var fare domain.RideFareModel
+	if err := r.rideFares().FindOne(ctx, bson.M{"_id": oid}).Decode(&fare); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("%w: %s", domain.ErrRideFareNotFound, id)
+		}
+
+		return nil, fmt.Errorf("failed to get ride fare: %w", err)
+	}
+
+	return &fare, nil
+}
+
+func (r *mongoRepository) DeleteRideFare(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrRideFareNotFound, id)
+	}
+
+	result, err := r.rideFares().DeleteOne(ctx, bson.M{"_id": oid})
+	if err != nil {
+		return fmt.Errorf("failed to delete ride fare: %w", err)
+	}
+
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrRideFareNotFound, id)
+	}
+
+	return nil
+}
+
+func (r *mongoRepository) ConsumeRideFare(ctx context.Context, id string, at time.Time) (*domain.RideFareModel, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRideFareNotFound, id)
+	}
+
+	filter := bson.M{
+		"_id":        oid,
+		"consumedAt": nil,
+		"expiresAt":  bson.M{"$gt": at},
+	}
+
+	var fare domain.RideFareModel
+	err = r.rideFares().FindOneAndUpdate(ctx, filter,
+		bson.M{"$set": bson.M{"consumedAt": at}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&fare)
+	if err == nil {
+		return &fare, nil
+	}
+
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("failed to consume ride fare: %w", err)
+	}
+
+	// nothing matched, look the fare up again to tell the caller why
+	current, err := r.GetRideFareByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := current.Usable(at); err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("failed to consume ride fare %s", id)
+}
+
+func (r *mongoRepository) ReleaseRideFare(ctx context.Context, id string) error {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("%w: %s", domain.ErrRideFareNotFound, id)
+	}
+
+	result, err := r.rideFares().UpdateOne(ctx, bson.M{"_id": oid}, bson.M{"$set": bson.M{"consumedAt": nil}})
+	if err != nil {
+		return fmt.Errorf("failed to release ride fare: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", domain.ErrRideFareNotFound, id)
+	}
+
+	return nil
+}
+
+func (r *mongoRepository) DeleteExpiredRideFares(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.rideFares().DeleteMany(ctx, bson.M{"expiresAt": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired ride fares: %w", err)
+	}
+
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,22 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+
+	"ride-sharing/services/trip-service/internal/domain"
+	"ride-sharing/services/trip-service/internal/infrastructure/repository"
+	"ride-sharing/services/trip-service/internal/infrastructure/repository/repositorytest"
+)
+
+// Set MONGODB_TEST_URI (e.g. mongodb://localhost:27017) to run it, it is skipped otherwise.
+func TestMongoRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) domain.TripRepository {
+		repo := repository.NewMongoRepository(repositorytest.MongoDatabase(t))
+		if err := repo.EnsureIndexes(context.Background()); err != nil {
+			t.Fatalf("EnsureIndexes: %v", err)
+		}
+
+		return repo
+	})
+}
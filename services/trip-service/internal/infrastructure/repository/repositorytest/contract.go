@@ -0,0 +1,626 @@
+/*
+Package repositorytest holds the contract every domain.TripRepository implementation
+must satisfy, so the in-memory repository stays a faithful stand-in for MongoDB.
+
+	func TestInmemRepository(t *testing.T) {
+		repositorytest.Run(t, func(t *testing.T) domain.TripRepository {
+			return repository.NewInmemRepository()
+		})
+	}
+*/
+package repositorytest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ride-sharing/services/trip-service/internal/domain"
+	"ride-sharing/shared/db"
+	pbd "ride-sharing/shared/proto/driver"
+	pb "ride-sharing/shared/proto/trip"
+
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Factory returns an empty repository for a single test.
+type Factory func(t *testing.T) domain.TripRepository
+
+// Run runs the whole contract suite against the repositories built by newRepo.
+func Run(t *testing.T, newRepo Factory) {
+	t.Run("CreateAndGetTrip", func(t *testing.T) { testCreateAndGetTrip(t, newRepo(t)) })
+	t.Run("GetUnknownTrip", func(t *testing.T) { testGetUnknownTrip(t, newRepo(t)) })
+	t.Run("UpdateTrip", func(t *testing.T) { testUpdateTrip(t, newRepo(t)) })
+	t.Run("UpdateUnknownTrip", func(t *testing.T) { testUpdateUnknownTrip(t, newRepo(t)) })
+	t.Run("SaveAndGetRideFare", func(t *testing.T) { testSaveAndGetRideFare(t, newRepo(t)) })
+	t.Run("GetUnknownRideFare", func(t *testing.T) { testGetUnknownRideFare(t, newRepo(t)) })
+	t.Run("UpdateTripVersionConflict", func(t *testing.T) { testUpdateTripVersionConflict(t, newRepo(t)) })
+	t.Run("ReturnedTripsAreCopies", func(t *testing.T) { testReturnedTripsAreCopies(t, newRepo(t)) })
+	t.Run("ListTripsByUser", func(t *testing.T) { testListTripsByUser(t, newRepo(t)) })
+	t.Run("ListTripsByStatus", func(t *testing.T) { testListTripsByStatus(t, newRepo(t)) })
+	t.Run("ListTripsByDriver", func(t *testing.T) { testListTripsByDriver(t, newRepo(t)) })
+	t.Run("ListTripsInvalidCursor", func(t *testing.T) { testListTripsInvalidCursor(t, newRepo(t)) })
+	t.Run("DeleteRideFare", func(t *testing.T) { testDeleteRideFare(t, newRepo(t)) })
+	t.Run("ConsumeRideFare", func(t *testing.T) { testConsumeRideFare(t, newRepo(t)) })
+	t.Run("ReleaseRideFare", func(t *testing.T) { testReleaseRideFare(t, newRepo(t)) })
+	t.Run("ConsumeExpiredRideFare", func(t *testing.T) { testConsumeExpiredRideFare(t, newRepo(t)) })
+	t.Run("DeleteExpiredRideFares", func(t *testing.T) { testDeleteExpiredRideFares(t, newRepo(t)) })
+	t.Run("ConcurrentUpdates", func(t *testing.T) { testConcurrentUpdates(t, newRepo(t)) })
+}
+
+// MongoDatabase returns a throwaway database on the server in MONGODB_TEST_URI,
+// skipping the test when the variable is unset or the server is unreachable.
+func MongoDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI not set, skipping mongo tests")
+	}
+
+	ctx := context.Background()
+	client, err := db.NewMongoClient(ctx, db.MongoConfig{URI: uri, ConnectTimeout: 2 * time.Second})
+	if err != nil {
+		t.Skipf("mongodb not reachable: %v", err)
+	}
+
+	database := client.Database(fmt.Sprintf("trip_service_test_%s", primitive.NewObjectID().Hex()))
+
+	t.Cleanup(func() {
+		_ = database.Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+
+	return database
+}
+
+const sampleRoute = `{"routes":[{"distance":1200,"duration":300,"geometry":{"coordinates":[[-122.41,37.77],[-122.42,37.78]]}}]}`
+
+func newFare(userID string) *domain.RideFareModel {
+	var route tripTypes.OsrmApiResponse
+	if err := json.Unmarshal([]byte(sampleRoute), &route); err != nil {
+		panic(err)
+	}
+
+	return &domain.RideFareModel{
+		ID:              primitive.NewObjectID(),
+		UserID:          userID,
+		PackageSlug:     "sedan",
+		TotalPriceCents: 1250,
+		Route:           &route,
+		ExpiresAt:       time.Now().UTC().Add(10 * time.Minute).Truncate(time.Millisecond),
+	}
+}
+
+func newTrip(userID string) *domain.TripModel {
+	// mongo keeps milliseconds only
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	return &domain.TripModel{
+		ID:     primitive.NewObjectID(),
+		UserID: userID,
+		Status: domain.TripStatusPending,
+		StatusHistory: []domain.TripStatusChange{
+			{Status: domain.TripStatusPending, At: now},
+		},
+		RideFare:  newFare(userID),
+		Driver:    &pb.TripDriver{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func testCreateAndGetTrip(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+	trip := newTrip("user-1")
+
+	if _, err := repo.CreateTrip(ctx, trip); err != nil {
+		t.Fatalf("CreateTrip: %v", err)
+	}
+
+	got, err := repo.GetTripByID(ctx, trip.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetTripByID: %v", err)
+	}
+
+	if got.ID != trip.ID || got.UserID != trip.UserID || got.Status != trip.Status {
+		t.Fatalf("GetTripByID = %+v, want %+v", got, trip)
+	}
+
+	if !got.CreatedAt.Equal(trip.CreatedAt) {
+		t.Fatalf("CreatedAt = %v, want %v", got.CreatedAt, trip.CreatedAt)
+	}
+
+	if len(got.StatusHistory) != 1 || got.StatusHistory[0].Status != domain.TripStatusPending {
+		t.Fatalf("StatusHistory = %+v", got.StatusHistory)
+	}
+
+	if got.RideFare == nil || got.RideFare.ID != trip.RideFare.ID || got.RideFare.TotalPriceCents != trip.RideFare.TotalPriceCents {
+		t.Fatalf("RideFare = %+v, want %+v", got.RideFare, trip.RideFare)
+	}
+
+	if got.RideFare.Route == nil || len(got.RideFare.Route.Routes) != 1 || got.RideFare.Route.Routes[0].Distance != 1200 {
+		t.Fatalf("Route = %+v", got.RideFare.Route)
+	}
+}
+
+func testGetUnknownTrip(t *testing.T, repo domain.TripRepository) {
+	_, err := repo.GetTripByID(context.Background(), primitive.NewObjectID().Hex())
+	if !errors.Is(err, domain.ErrTripNotFound) {
+		t.Fatalf("GetTripByID error = %v, want %v", err, domain.ErrTripNotFound)
+	}
+}
+
+func testUpdateTrip(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+	trip := newTrip("user-1")
+
+	if _, err := repo.CreateTrip(ctx, trip); err != nil {
+		t.Fatalf("CreateTrip: %v", err)
+	}
+
+	if err := trip.Offer("driver-1", time.Now().UTC().Truncate(time.Millisecond)); err != nil {
+		t.Fatalf("Offer: %v", err)
+	}
+
+	if err := repo.UpdateTrip(ctx, trip); err != nil {
+		t.Fatalf("UpdateTrip: %v", err)
+	}
+
+	offered, err := repo.GetTripByID(ctx, trip.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetTripByID: %v", err)
+	}
+
+	if offered.Status != domain.TripStatusDriverOffered || offered.OfferedDriverID != "driver-1" {
+		t.Fatalf("offered trip is %q to %q, want %q to driver-1", offered.Status, offered.OfferedDriverID, domain.TripStatusDriverOffered)
+	}
+
+	if err := offered.Accept(&pbd.Driver{Id: "driver-1", Name: "Lando Norris"}, time.Now().UTC().Truncate(time.Millisecond)); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+
+	if err := repo.UpdateTrip(ctx, offered); err != nil {
+		t.Fatalf("UpdateTrip: %v", err)
+	}
+
+	got, err := repo.GetTripByID(ctx, trip.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetTripByID: %v", err)
+	}
+
+	if got.Status != domain.TripStatusAccepted {
+		t.Fatalf("Status = %q, want %q", got.Status, domain.TripStatusAccepted)
+	}
+
+	if got.Driver.GetId() != "driver-1" {
+		t.Fatalf("Driver = %+v", got.Driver)
+	}
+
+	if _, ok := got.StatusChangedAt(domain.TripStatusAccepted); !ok {
+		t.Fatalf("StatusHistory = %+v, missing accepted", got.StatusHistory)
+	}
+}
+
+func testUpdateUnknownTrip(t *testing.T, repo domain.TripRepository) {
+	err := repo.UpdateTrip(context.Background(), newTrip("user-1"))
+	if !errors.Is(err, domain.ErrTripNotFound) {
+		t.Fatalf("UpdateTrip error = %v, want %v", err, domain.ErrTripNotFound)
+	}
+}
+
+func testSaveAndGetRideFare(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+	fare := newFare("user-1")
+
+	if err := repo.SaveRideFare(ctx, fare); err != nil {
+		t.Fatalf("SaveRideFare: %v", err)
+	}
+
+	got, err := repo.GetRideFareByID(ctx, fare.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetRideFareByID: %v", err)
+	}
+
+	if got.ID != fare.ID || got.UserID != fare.UserID || got.PackageSlug != fare.PackageSlug || got.TotalPriceCents != fare.TotalPriceCents {
+		t.Fatalf("GetRideFareByID = %+v, want %+v", got, fare)
+	}
+}
+
+func testGetUnknownRideFare(t *testing.T, repo domain.TripRepository) {
+	_, err := repo.GetRideFareByID(context.Background(), primitive.NewObjectID().Hex())
+	if !errors.Is(err, domain.ErrRideFareNotFound) {
+		t.Fatalf("GetRideFareByID error = %v, want %v", err, domain.ErrRideFareNotFound)
+	}
+}
+
+func testUpdateTripVersionConflict(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+	trip := newTrip("user-1")
+
+	if _, err := repo.CreateTrip(ctx, trip); err != nil {
+		t.Fatalf("CreateTrip: %v", err)
+	}
+
+	first, err := repo.GetTripByID(ctx, trip.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetTripByID: %v", err)
+	}
+
+	second, err := repo.GetTripByID(ctx, trip.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetTripByID: %v", err)
+	}
+
+	first.Status = domain.TripStatusDriverOffered
+	if err := repo.UpdateTrip(ctx, first); err != nil {
+		t.Fatalf("UpdateTrip: %v", err)
+	}
+
+	if first.Version != second.Version+1 {
+		t.Fatalf("Version = %d, want %d", first.Version, second.Version+1)
+	}
+
+	second.Status = domain.TripStatusCancelled
+	if err := repo.UpdateTrip(ctx, second); !errors.Is(err, domain.ErrTripVersionConflict) {
+		t.Fatalf("UpdateTrip error = %v, want %v", err, domain.ErrTripVersionConflict)
+	}
+
+	got, err := repo.GetTripByID(ctx, trip.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetTripByID: %v", err)
+	}
+
+	if got.Status != domain.TripStatusDriverOffered || got.Version != first.Version {
+		t.Fatalf("stored trip = %q v%d, want %q v%d", got.Status, got.Version, domain.TripStatusDriverOffered, first.Version)
+	}
+}
+
+func testReturnedTripsAreCopies(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+	trip := newTrip("user-1")
+
+	if _, err := repo.CreateTrip(ctx, trip); err != nil {
+		t.Fatalf("CreateTrip: %v", err)
+	}
+
+	trip.Status = domain.TripStatusCancelled
+
+	got, err := repo.GetTripByID(ctx, trip.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetTripByID: %v", err)
+	}
+
+	got.Status = domain.TripStatusCompleted
+	got.Driver.Id = "driver-1"
+
+	again, err := repo.GetTripByID(ctx, trip.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetTripByID: %v", err)
+	}
+
+	if again.Status != domain.TripStatusPending || again.Driver.GetId() != "" {
+		t.Fatalf("stored trip changed without UpdateTrip: %+v", again)
+	}
+}
+
+func testListTripsByUser(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+
+	var created []*domain.TripModel
+	for i := 0; i < 5; i++ {
+		trip := newTrip("user-1")
+		if _, err := repo.CreateTrip(ctx, trip); err != nil {
+			t.Fatalf("CreateTrip: %v", err)
+		}
+		created = append(created, trip)
+	}
+
+	if _, err := repo.CreateTrip(ctx, newTrip("user-2")); err != nil {
+		t.Fatalf("CreateTrip: %v", err)
+	}
+
+	var listed []*domain.TripModel
+	page := domain.PageRequest{Limit: 2}
+	pages := 0
+
+	for {
+		result, err := repo.ListTripsByUser(ctx, "user-1", page)
+		if err != nil {
+			t.Fatalf("ListTripsByUser: %v", err)
+		}
+
+		pages++
+		listed = append(listed, result.Trips...)
+
+		if result.NextCursor == "" {
+			break
+		}
+
+		if pages > 5 {
+			t.Fatalf("ListTripsByUser never ends")
+		}
+
+		page.Cursor = result.NextCursor
+	}
+
+	if pages != 3 || len(listed) != len(created) {
+		t.Fatalf("listed %d trips in %d pages, want %d in 3", len(listed), pages, len(created))
+	}
+
+	// newest first
+	for i, trip := range listed {
+		want := created[len(created)-1-i]
+		if trip.ID != want.ID {
+			t.Fatalf("trip %d = %s, want %s", i, trip.ID.Hex(), want.ID.Hex())
+		}
+	}
+
+	result, err := repo.ListTrips(ctx, domain.TripFilter{UserID: "nobody"}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("ListTrips: %v", err)
+	}
+
+	if len(result.Trips) != 0 || result.NextCursor != "" {
+		t.Fatalf("ListTrips(nobody) = %+v", result)
+	}
+}
+
+func testListTripsByStatus(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+
+	pending := newTrip("user-1")
+	accepted := newTrip("user-2")
+	accepted.Status = domain.TripStatusAccepted
+
+	for _, trip := range []*domain.TripModel{pending, accepted} {
+		if _, err := repo.CreateTrip(ctx, trip); err != nil {
+			t.Fatalf("CreateTrip: %v", err)
+		}
+	}
+
+	result, err := repo.ListTripsByStatus(ctx, domain.TripStatusAccepted, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("ListTripsByStatus: %v", err)
+	}
+
+	if len(result.Trips) != 1 || result.Trips[0].ID != accepted.ID {
+		t.Fatalf("ListTripsByStatus(accepted) = %+v", result.Trips)
+	}
+
+	result, err = repo.ListTrips(ctx, domain.TripFilter{
+		UserID:   "user-1",
+		Statuses: []domain.TripStatus{domain.TripStatusAccepted, domain.TripStatusPending},
+	}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("ListTrips: %v", err)
+	}
+
+	if len(result.Trips) != 1 || result.Trips[0].ID != pending.ID {
+		t.Fatalf("ListTrips(user-1, accepted or pending) = %+v", result.Trips)
+	}
+}
+
+func testListTripsByDriver(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+
+	assigned := newTrip("user-1")
+	assigned.Status = domain.TripStatusAccepted
+	assigned.Driver = &pb.TripDriver{Id: "driver-1", Name: "Lando"}
+
+	completed := newTrip("user-2")
+	completed.Status = domain.TripStatusCompleted
+	completed.Driver = &pb.TripDriver{Id: "driver-1", Name: "Lando"}
+
+	for _, trip := range []*domain.TripModel{assigned, completed, newTrip("user-3")} {
+		if _, err := repo.CreateTrip(ctx, trip); err != nil {
+			t.Fatalf("CreateTrip: %v", err)
+		}
+	}
+
+	result, err := repo.ListTrips(ctx, domain.TripFilter{DriverID: "driver-1"}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("ListTrips: %v", err)
+	}
+
+	if len(result.Trips) != 2 || result.Trips[0].ID != completed.ID || result.Trips[1].ID != assigned.ID {
+		t.Fatalf("ListTrips(driver-1) = %+v", result.Trips)
+	}
+
+	result, err = repo.ListTrips(ctx, domain.TripFilter{
+		DriverID: "driver-1",
+		Statuses: []domain.TripStatus{domain.TripStatusCompleted},
+	}, domain.PageRequest{})
+	if err != nil {
+		t.Fatalf("ListTrips: %v", err)
+	}
+
+	if len(result.Trips) != 1 || result.Trips[0].ID != completed.ID {
+		t.Fatalf("ListTrips(driver-1, completed) = %+v", result.Trips)
+	}
+}
+
+func testListTripsInvalidCursor(t *testing.T, repo domain.TripRepository) {
+	_, err := repo.ListTrips(context.Background(), domain.TripFilter{UserID: "user-1"}, domain.PageRequest{Cursor: "not-a-cursor"})
+	if !errors.Is(err, domain.ErrInvalidCursor) {
+		t.Fatalf("ListTrips error = %v, want %v", err, domain.ErrInvalidCursor)
+	}
+}
+
+func testDeleteRideFare(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+	fare := newFare("user-1")
+
+	if err := repo.SaveRideFare(ctx, fare); err != nil {
+		t.Fatalf("SaveRideFare: %v", err)
+	}
+
+	if err := repo.DeleteRideFare(ctx, fare.ID.Hex()); err != nil {
+		t.Fatalf("DeleteRideFare: %v", err)
+	}
+
+	if _, err := repo.GetRideFareByID(ctx, fare.ID.Hex()); !errors.Is(err, domain.ErrRideFareNotFound) {
+		t.Fatalf("GetRideFareByID error = %v, want %v", err, domain.ErrRideFareNotFound)
+	}
+
+	if err := repo.DeleteRideFare(ctx, fare.ID.Hex()); !errors.Is(err, domain.ErrRideFareNotFound) {
+		t.Fatalf("DeleteRideFare error = %v, want %v", err, domain.ErrRideFareNotFound)
+	}
+}
+
+func testConsumeRideFare(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+	fare := newFare("user-1")
+
+	if err := repo.SaveRideFare(ctx, fare); err != nil {
+		t.Fatalf("SaveRideFare: %v", err)
+	}
+
+	got, err := repo.ConsumeRideFare(ctx, fare.ID.Hex(), time.Now())
+	if err != nil {
+		t.Fatalf("ConsumeRideFare: %v", err)
+	}
+
+	if got.ConsumedAt == nil || got.ID != fare.ID {
+		t.Fatalf("ConsumeRideFare = %+v, want the consumed fare", got)
+	}
+
+	if _, err := repo.ConsumeRideFare(ctx, fare.ID.Hex(), time.Now()); !errors.Is(err, domain.ErrRideFareConsumed) {
+		t.Fatalf("second ConsumeRideFare error = %v, want %v", err, domain.ErrRideFareConsumed)
+	}
+
+	if _, err := repo.ConsumeRideFare(ctx, primitive.NewObjectID().Hex(), time.Now()); !errors.Is(err, domain.ErrRideFareNotFound) {
+		t.Fatalf("ConsumeRideFare error = %v, want %v", err, domain.ErrRideFareNotFound)
+	}
+}
+
+func testReleaseRideFare(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+	fare := newFare("user-1")
+
+	if err := repo.SaveRideFare(ctx, fare); err != nil {
+		t.Fatalf("SaveRideFare: %v", err)
+	}
+
+	if _, err := repo.ConsumeRideFare(ctx, fare.ID.Hex(), time.Now()); err != nil {
+		t.Fatalf("ConsumeRideFare: %v", err)
+	}
+
+	if err := repo.ReleaseRideFare(ctx, fare.ID.Hex()); err != nil {
+		t.Fatalf("ReleaseRideFare: %v", err)
+	}
+
+	if _, err := repo.ConsumeRideFare(ctx, fare.ID.Hex(), time.Now()); err != nil {
+		t.Fatalf("ConsumeRideFare after release: %v", err)
+	}
+
+	if err := repo.ReleaseRideFare(ctx, primitive.NewObjectID().Hex()); !errors.Is(err, domain.ErrRideFareNotFound) {
+		t.Fatalf("ReleaseRideFare error = %v, want %v", err, domain.ErrRideFareNotFound)
+	}
+}
+
+func testConsumeExpiredRideFare(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+	fare := newFare("user-1")
+
+	if err := repo.SaveRideFare(ctx, fare); err != nil {
+		t.Fatalf("SaveRideFare: %v", err)
+	}
+
+	if _, err := repo.ConsumeRideFare(ctx, fare.ID.Hex(), fare.ExpiresAt.Add(time.Second)); !errors.Is(err, domain.ErrRideFareExpired) {
+		t.Fatalf("ConsumeRideFare error = %v, want %v", err, domain.ErrRideFareExpired)
+	}
+
+	got, err := repo.GetRideFareByID(ctx, fare.ID.Hex())
+	if err != nil {
+		t.Fatalf("GetRideFareByID: %v", err)
+	}
+
+	if got.ConsumedAt != nil {
+		t.Fatalf("expired fare was consumed at %v", got.ConsumedAt)
+	}
+}
+
+func testDeleteExpiredRideFares(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+
+	expired := newFare("user-1")
+	expired.ExpiresAt = time.Now().UTC().Add(-time.Minute).Truncate(time.Millisecond)
+	fresh := newFare("user-1")
+
+	for _, f := range []*domain.RideFareModel{expired, fresh} {
+		if err := repo.SaveRideFare(ctx, f); err != nil {
+			t.Fatalf("SaveRideFare: %v", err)
+		}
+	}
+
+	deleted, err := repo.DeleteExpiredRideFares(ctx, time.Now())
+	if err != nil {
+		t.Fatalf("DeleteExpiredRideFares: %v", err)
+	}
+
+	if deleted != 1 {
+		t.Fatalf("DeleteExpiredRideFares = %d, want 1", deleted)
+	}
+
+	if _, err := repo.GetRideFareByID(ctx, expired.ID.Hex()); !errors.Is(err, domain.ErrRideFareNotFound) {
+		t.Fatalf("GetRideFareByID error = %v, want %v", err, domain.ErrRideFareNotFound)
+	}
+
+	if _, err := repo.GetRideFareByID(ctx, fresh.ID.Hex()); err != nil {
+		t.Fatalf("GetRideFareByID: %v", err)
+	}
+}
+
+// testConcurrentUpdates races readers and writers, exactly one writer per version must win.
+func testConcurrentUpdates(t *testing.T, repo domain.TripRepository) {
+	ctx := context.Background()
+	trip := newTrip("user-1")
+
+	if _, err := repo.CreateTrip(ctx, trip); err != nil {
+		t.Fatalf("CreateTrip: %v", err)
+	}
+
+	const workers = 8
+	var wg sync.WaitGroup
+	var succeeded atomic.Int32
+
+	start := make(chan struct{})
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			copied := trip.Clone()
+			copied.Status = domain.TripStatusDriverOffered
+			<-start
+
+			if _, err := repo.GetTripByID(ctx, trip.ID.Hex()); err != nil {
+				t.Errorf("GetTripByID: %v", err)
+			}
+
+			err := repo.UpdateTrip(ctx, copied)
+			if err == nil {
+				succeeded.Add(1)
+			} else if !errors.Is(err, domain.ErrTripVersionConflict) {
+				t.Errorf("UpdateTrip: %v", err)
+			}
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	if got := succeeded.Load(); got != 1 {
+		t.Fatalf("%d concurrent updates succeeded, want 1", got)
+	}
+}
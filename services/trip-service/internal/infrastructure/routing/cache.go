@@ -0,0 +1,193 @@
+package routing
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"math"
+	"ride-sharing/services/trip-service/internal/domain"
+	"ride-sharing/shared/types"
+	"sync"
+	"time"
+
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+)
+
+const (
+	DefaultCacheCapacity = 1000
+	DefaultCacheTTL      = 10 * time.Minute
+	// DefaultCachePrecision rounds coordinates to 4 decimals, about 11 meters
+	DefaultCachePrecision = 4
+)
+
+type CacheConfig struct {
+	Capacity int
+	TTL      time.Duration
+	// Precision is the number of decimals coordinates are rounded to before lookup,
+	// whole degrees would be far too coarse so 0 means the default
+	Precision int
+}
+
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+type cacheEntry struct {
+	key       string
+	route     *tripTypes.OsrmApiResponse
+	expiresAt time.Time
+}
+
+// cachedProvider is an LRU cache with expiry in front of another RouteProvider.
+// Cached routes are shared between callers and must not be modified.
+type cachedProvider struct {
+	next      domain.RouteProvider
+	capacity  int
+	ttl       time.Duration
+	precision int
+
+	entries map[string]*list.Element
+	lru     *list.List // front is the most recently used
+	stats   CacheStats
+	mu      sync.Mutex
+
+	now func() time.Time
+}
+
+func NewCachedProvider(next domain.RouteProvider, cfg CacheConfig) *cachedProvider {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = DefaultCacheCapacity
+	}
+
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultCacheTTL
+	}
+
+	if cfg.Precision <= 0 {
+		cfg.Precision = DefaultCachePrecision
+	}
+
+	return &cachedProvider{
+		next:      next,
+		capacity:  cfg.Capacity,
+		ttl:       cfg.TTL,
+		precision: cfg.Precision,
+		entries:   make(map[string]*list.Element),
+		lru:       list.New(),
+		now:       time.Now,
+	}
+}
+
+type bypassCacheKey struct{}
+
+// WithoutCache makes the route lookups done with ctx skip the cache.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassCacheKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassCacheKey{}).(bool)
+	return bypass
+}
+
+func (c *cachedProvider) GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error) {
+	if cacheBypassed(ctx) {
+		return c.next.GetRoute(ctx, pickup, destination)
+	}
+
+	key := c.key(pickup, destination)
+
+	if route, ok := c.get(key); ok {
+		return route, nil
+	}
+
+	route, err := c.next.GetRoute(ctx, pickup, destination)
+	if err != nil {
+		return nil, err
+	}
+
+	c.put(key, route)
+
+	return route, nil
+}
+
+// Stats returns the cache counters so far.
+func (c *cachedProvider) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.lru.Len()
+
+	return stats
+}
+
+func (c *cachedProvider) key(pickup, destination *types.Coordinate) string {
+	return fmt.Sprintf("%s,%s;%s,%s",
+		c.round(pickup.Latitude), c.round(pickup.Longitude),
+		c.round(destination.Latitude), c.round(destination.Longitude),
+	)
+}
+
+func (c *cachedProvider) round(v float64) string {
+	factor := math.Pow(10, float64(c.precision))
+	return fmt.Sprintf("%.*f", c.precision, math.Round(v*factor)/factor)
+}
+
+func (c *cachedProvider) get(key string) (*tripTypes.OsrmApiResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if c.now().After(entry.expiresAt) {
+		c.removeElement(elem)
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	c.stats.Hits++
+
+	return entry.route, true
+}
+
+func (c *cachedProvider) put(key string, route *tripTypes.OsrmApiResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(c.ttl)
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.route = route
+		entry.expiresAt = expiresAt
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(&cacheEntry{
+		key:       key,
+		route:     route,
+		expiresAt: expiresAt,
+	})
+
+	for c.lru.Len() > c.capacity {
+		c.removeElement(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Must be called with c.mu held.
+func (c *cachedProvider) removeElement(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+}
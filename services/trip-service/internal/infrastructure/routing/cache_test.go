@@ -0,0 +1,196 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ride-sharing/shared/types"
+
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+)
+
+// countingProvider returns a new route on every call, so cached routes can be told apart.
+type countingProvider struct {
+	calls int
+	err   error
+}
+
+func (p *countingProvider) GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return &tripTypes.OsrmApiResponse{
+		Routes: []tripTypes.OsrmRoute{{Distance: float64(p.calls)}},
+	}, nil
+}
+
+var (
+	home   = &types.Coordinate{Latitude: 52.52001, Longitude: 13.40495}
+	office = &types.Coordinate{Latitude: 52.50000, Longitude: 13.45000}
+	gym    = &types.Coordinate{Latitude: 52.53000, Longitude: 13.38000}
+	// nearHome rounds to the same 4 decimals as home
+	nearHome = &types.Coordinate{Latitude: 52.52003, Longitude: 13.40501}
+)
+
+type lookup struct {
+	pickup, destination *types.Coordinate
+	// after is how long after the previous lookup this one happens
+	after  time.Duration
+	bypass bool
+	// wantCall is whether the lookup reaches the router
+	wantCall bool
+}
+
+func TestCachedProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       CacheConfig
+		lookups   []lookup
+		wantStats CacheStats
+	}{
+		{
+			name: "repeated lookup is a hit",
+			cfg:  CacheConfig{Capacity: 10, TTL: time.Minute, Precision: 4},
+			lookups: []lookup{
+				{pickup: home, destination: office, wantCall: true},
+				{pickup: home, destination: office},
+			},
+			wantStats: CacheStats{Hits: 1, Misses: 1, Size: 1},
+		},
+		{
+			name: "nearby coordinates share a route",
+			cfg:  CacheConfig{Capacity: 10, TTL: time.Minute, Precision: 4},
+			lookups: []lookup{
+				{pickup: home, destination: office, wantCall: true},
+				{pickup: nearHome, destination: office},
+			},
+			wantStats: CacheStats{Hits: 1, Misses: 1, Size: 1},
+		},
+		{
+			name: "finer precision keeps them apart",
+			cfg:  CacheConfig{Capacity: 10, TTL: time.Minute, Precision: 5},
+			lookups: []lookup{
+				{pickup: home, destination: office, wantCall: true},
+				{pickup: nearHome, destination: office, wantCall: true},
+			},
+			wantStats: CacheStats{Misses: 2, Size: 2},
+		},
+		{
+			name: "zero config uses the default precision",
+			cfg:  CacheConfig{},
+			lookups: []lookup{
+				{pickup: home, destination: office, wantCall: true},
+				{pickup: nearHome, destination: office},
+				// same whole degrees as home
+				{pickup: gym, destination: office, wantCall: true},
+			},
+			wantStats: CacheStats{Hits: 1, Misses: 2, Size: 2},
+		},
+		{
+			name: "direction matters",
+			cfg:  CacheConfig{Capacity: 10, TTL: time.Minute, Precision: 4},
+			lookups: []lookup{
+				{pickup: home, destination: office, wantCall: true},
+				{pickup: office, destination: home, wantCall: true},
+			},
+			wantStats: CacheStats{Misses: 2, Size: 2},
+		},
+		{
+			name: "expired route is fetched again",
+			cfg:  CacheConfig{Capacity: 10, TTL: time.Minute, Precision: 4},
+			lookups: []lookup{
+				{pickup: home, destination: office, wantCall: true},
+				{pickup: home, destination: office, after: 30 * time.Second},
+				{pickup: home, destination: office, after: 31 * time.Second, wantCall: true},
+				{pickup: home, destination: office, after: 30 * time.Second},
+			},
+			wantStats: CacheStats{Hits: 2, Misses: 2, Size: 1},
+		},
+		{
+			name: "least recently used is evicted",
+			cfg:  CacheConfig{Capacity: 2, TTL: time.Minute, Precision: 4},
+			lookups: []lookup{
+				{pickup: home, destination: office, wantCall: true},
+				{pickup: home, destination: gym, wantCall: true},
+				// office is used again, so gym is the oldest
+				{pickup: home, destination: office},
+				{pickup: office, destination: gym, wantCall: true},
+				{pickup: home, destination: office},
+				{pickup: home, destination: gym, wantCall: true},
+			},
+			wantStats: CacheStats{Hits: 2, Misses: 4, Evictions: 2, Size: 2},
+		},
+		{
+			name: "bypass skips the cache and its counters",
+			cfg:  CacheConfig{Capacity: 10, TTL: time.Minute, Precision: 4},
+			lookups: []lookup{
+				{pickup: home, destination: office, wantCall: true},
+				{pickup: home, destination: office, bypass: true, wantCall: true},
+				{pickup: home, destination: gym, bypass: true, wantCall: true},
+				{pickup: home, destination: office},
+			},
+			wantStats: CacheStats{Hits: 1, Misses: 1, Size: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := &countingProvider{}
+			cache := NewCachedProvider(router, tt.cfg)
+
+			now := time.Now()
+			cache.now = func() time.Time { return now }
+
+			for i, l := range tt.lookups {
+				now = now.Add(l.after)
+
+				ctx := context.Background()
+				if l.bypass {
+					ctx = WithoutCache(ctx)
+				}
+
+				calls := router.calls
+
+				route, err := cache.GetRoute(ctx, l.pickup, l.destination)
+				if err != nil {
+					t.Fatalf("lookup %d: GetRoute: %v", i, err)
+				}
+
+				if called := router.calls > calls; called != l.wantCall {
+					t.Fatalf("lookup %d: reached the router = %v, want %v", i, called, l.wantCall)
+				}
+
+				if route == nil || len(route.Routes) != 1 {
+					t.Fatalf("lookup %d: route = %+v", i, route)
+				}
+			}
+
+			if got := cache.Stats(); got != tt.wantStats {
+				t.Fatalf("Stats = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestCachedProviderDoesNotCacheErrors(t *testing.T) {
+	router := &countingProvider{err: errors.New("router down")}
+	cache := NewCachedProvider(router, CacheConfig{})
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GetRoute(context.Background(), home, office); err == nil {
+			t.Fatal("GetRoute succeeded with the router down")
+		}
+	}
+
+	if router.calls != 2 {
+		t.Fatalf("router called %d times, want 2", router.calls)
+	}
+
+	if size := cache.Stats().Size; size != 0 {
+		t.Fatalf("cache holds %d routes after errors", size)
+	}
+}
@@ -0,0 +1,94 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"ride-sharing/shared/types"
+	shareutil "ride-sharing/shared/util"
+
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+)
+
+type LocalMode string
+
+const (
+	// LocalModeStraight goes in a straight line from pickup to destination
+	LocalModeStraight LocalMode = "straight"
+	// LocalModeGrid goes north/south first and then east/west, like on a street grid
+	LocalModeGrid LocalMode = "grid"
+
+	DefaultLocalSpeedKmh = 30.0
+	// localStepMeters is the spacing of the points along the route geometry
+	localStepMeters = 100.0
+)
+
+type LocalConfig struct {
+	Mode     LocalMode
+	SpeedKmh float64
+}
+
+// localProvider computes routes without any network call, the same input always
+// gives the same route. Useful offline and in tests.
+type localProvider struct {
+	mode     LocalMode
+	speedKmh float64
+}
+
+func NewLocalProvider(cfg LocalConfig) (*localProvider, error) {
+	mode := cfg.Mode
+	if mode == "" {
+		mode = LocalModeGrid
+	}
+
+	if mode != LocalModeStraight && mode != LocalModeGrid {
+		return nil, fmt.Errorf("unknown local route mode %q", mode)
+	}
+
+	speed := cfg.SpeedKmh
+	if speed <= 0 {
+		speed = DefaultLocalSpeedKmh
+	}
+
+	return &localProvider{
+		mode:     mode,
+		speedKmh: speed,
+	}, nil
+}
+
+func (p *localProvider) GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error) {
+	waypoints := []*types.Coordinate{pickup}
+	if p.mode == LocalModeGrid {
+		waypoints = append(waypoints, &types.Coordinate{
+			Latitude:  destination.Latitude,
+			Longitude: pickup.Longitude,
+		})
+	}
+	waypoints = append(waypoints, destination)
+
+	var route tripTypes.OsrmRoute
+	route.Geometry.Coordinates = [][]float64{{pickup.Longitude, pickup.Latitude}}
+
+	for i := 1; i < len(waypoints); i++ {
+		from, to := waypoints[i-1], waypoints[i]
+		legMeters := shareutil.HaversineKm(from.Latitude, from.Longitude, to.Latitude, to.Longitude) * 1000
+		route.Distance += legMeters
+
+		steps := int(math.Max(1, math.Ceil(legMeters/localStepMeters)))
+		for s := 1; s <= steps; s++ {
+			f := float64(s) / float64(steps)
+			route.Geometry.Coordinates = append(route.Geometry.Coordinates, []float64{
+				from.Longitude + (to.Longitude-from.Longitude)*f,
+				from.Latitude + (to.Latitude-from.Latitude)*f,
+			})
+		}
+	}
+
+	// meters / (meters per second)
+	route.Duration = route.Distance / (p.speedKmh * 1000 / 3600)
+
+	return &tripTypes.OsrmApiResponse{
+		Code:   "Ok",
+		Routes: []tripTypes.OsrmRoute{route},
+	}, nil
+}
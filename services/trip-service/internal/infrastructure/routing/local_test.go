@@ -0,0 +1,53 @@
+package routing
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestLocalProviderIsDeterministic(t *testing.T) {
+	for _, mode := range []LocalMode{LocalModeStraight, LocalModeGrid} {
+		t.Run(string(mode), func(t *testing.T) {
+			provider, err := NewLocalProvider(LocalConfig{Mode: mode})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			first, err := provider.GetRoute(context.Background(), home, office)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			second, err := provider.GetRoute(context.Background(), home, office)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(first, second) {
+				t.Errorf("same input gave different routes:\n%+v\n%+v", first, second)
+			}
+
+			route := first.Routes[0]
+			if route.Distance <= 0 || route.Duration <= 0 {
+				t.Errorf("got distance %v and duration %v", route.Distance, route.Duration)
+			}
+
+			coords := route.Geometry.Coordinates
+			start, end := coords[0], coords[len(coords)-1]
+			if start[0] != home.Longitude || start[1] != home.Latitude {
+				t.Errorf("route starts at %v, want the pickup", start)
+			}
+			if math.Abs(end[0]-office.Longitude) > 1e-9 || math.Abs(end[1]-office.Latitude) > 1e-9 {
+				t.Errorf("route ends at %v, want the destination", end)
+			}
+		})
+	}
+}
+
+func TestLocalProviderUnknownMode(t *testing.T) {
+	if _, err := NewLocalProvider(LocalConfig{Mode: "teleport"}); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,110 @@
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"ride-sharing/services/trip-service/internal/domain"
+	"ride-sharing/shared/types"
+	"strings"
+	"time"
+
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+)
+
+const (
+	DefaultOSRMBaseURL = "http://router.project-osrm.org"
+	DefaultOSRMTimeout = 5 * time.Second
+)
+
+type OSRMConfig struct {
+	BaseURL string
+	Timeout time.Duration
+	// Profile is the OSRM routing profile, "driving" when empty
+	Profile string
+}
+
+// osrmProvider asks an OSRM server for the route.
+type osrmProvider struct {
+	baseURL string
+	profile string
+	client  *http.Client
+}
+
+func NewOSRMProvider(cfg OSRMConfig) *osrmProvider {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultOSRMBaseURL
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultOSRMTimeout
+	}
+
+	profile := cfg.Profile
+	if profile == "" {
+		profile = "driving"
+	}
+
+	return &osrmProvider{
+		baseURL: baseURL,
+		profile: profile,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (p *osrmProvider) GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error) {
+	url := fmt.Sprintf("%s/route/v1/%s/%f,%f;%f,%f?overview=full&geometries=geojson",
+		p.baseURL, p.profile,
+		pickup.Longitude, pickup.Latitude,
+		destination.Longitude, destination.Latitude,
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OSRM request: %v", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the route from OSRM: %v", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response: %v", err)
+	}
+
+	var routeResp tripTypes.OsrmApiResponse
+	jsonErr := json.Unmarshal(body, &routeResp)
+
+	switch {
+	case resp.StatusCode == http.StatusBadRequest && routeResp.Code == "NoRoute":
+		return nil, fmt.Errorf("%w: %s", domain.ErrNoRoute, routeResp.Message)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("OSRM returned %d: %s", resp.StatusCode, snippet(body))
+	case jsonErr != nil:
+		return nil, fmt.Errorf("failed to parse response: %v", jsonErr)
+	case routeResp.Code != "" && routeResp.Code != "Ok":
+		return nil, fmt.Errorf("OSRM returned %s: %s", routeResp.Code, routeResp.Message)
+	case len(routeResp.Routes) == 0:
+		return nil, domain.ErrNoRoute
+	}
+
+	return &routeResp, nil
+}
+
+// snippet keeps error messages readable when the server returns an HTML page.
+func snippet(body []byte) string {
+	const max = 200
+	if len(body) > max {
+		return string(body[:max]) + "..."
+	}
+
+	return string(body)
+}
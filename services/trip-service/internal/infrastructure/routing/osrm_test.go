@@ -0,0 +1,106 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ride-sharing/services/trip-service/internal/domain"
+)
+
+func TestOSRMProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   string
+		wantRoute bool
+		noRoute   bool
+	}{
+		{
+			name:      "route found",
+			status:    http.StatusOK,
+			body:      `{"code":"Ok","routes":[{"distance":1200,"duration":180,"geometry":{"coordinates":[[13.4,52.5],[13.45,52.5]]}}]}`,
+			wantRoute: true,
+		},
+		{
+			name:    "no route",
+			status:  http.StatusBadRequest,
+			body:    `{"code":"NoRoute","message":"Impossible route between points"}`,
+			noRoute: true,
+		},
+		{
+			name:    "empty routes",
+			status:  http.StatusOK,
+			body:    `{"code":"Ok","routes":[]}`,
+			noRoute: true,
+		},
+		{
+			name:    "bad request",
+			status:  http.StatusBadRequest,
+			body:    `{"code":"InvalidQuery","message":"Query string malformed"}`,
+			wantErr: "OSRM returned 400",
+		},
+		{
+			name:    "server error",
+			status:  http.StatusBadGateway,
+			body:    `<html>bad gateway</html>`,
+			wantErr: "OSRM returned 502",
+		},
+		{
+			name:    "error code with 200",
+			status:  http.StatusOK,
+			body:    `{"code":"InvalidValue","message":"Invalid coordinate value"}`,
+			wantErr: "OSRM returned InvalidValue",
+		},
+		{
+			name:    "not json",
+			status:  http.StatusOK,
+			body:    `not json`,
+			wantErr: "failed to parse response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider := NewOSRMProvider(OSRMConfig{BaseURL: server.URL + "/"})
+			route, err := provider.GetRoute(context.Background(), home, office)
+
+			if want := "/route/v1/driving/13.404950,52.520010;13.450000,52.500000"; path != want {
+				t.Errorf("requested %s, want %s", path, want)
+			}
+
+			switch {
+			case tt.wantRoute:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(route.Routes) != 1 || route.Routes[0].Distance != 1200 {
+					t.Errorf("got routes %+v", route.Routes)
+				}
+			case tt.noRoute:
+				if !errors.Is(err, domain.ErrNoRoute) {
+					t.Errorf("got error %v, want %v", err, domain.ErrNoRoute)
+				}
+			default:
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("got error %v, want %q", err, tt.wantErr)
+				}
+				if errors.Is(err, domain.ErrNoRoute) {
+					t.Errorf("%v shouldn't be reported as no route", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type RoundingMode string
+
+const (
+	RoundUp      RoundingMode = "up"
+	RoundDown    RoundingMode = "down"
+	RoundNearest RoundingMode = "nearest"
+)
+
+// Rounding rounds the total to a multiple of IncrementCents.
+type Rounding struct {
+	Mode           RoundingMode `json:"mode" yaml:"mode"`
+	IncrementCents int64        `json:"incrementCents" yaml:"incrementCents"`
+}
+
+// PackagePricing holds the rates of a car package, all amounts are in cents.
+type PackagePricing struct {
+	Slug             string  `json:"slug" yaml:"slug"`
+	BaseFareCents    float64 `json:"baseFareCents" yaml:"baseFareCents"`
+	PerKmCents       float64 `json:"perKmCents" yaml:"perKmCents"`
+	PerMinuteCents   float64 `json:"perMinuteCents" yaml:"perMinuteCents"`
+	MinimumFareCents float64 `json:"minimumFareCents" yaml:"minimumFareCents"`
+	BookingFeeCents  float64 `json:"bookingFeeCents" yaml:"bookingFeeCents"`
+}
+
+type Config struct {
+	Rounding Rounding `json:"rounding" yaml:"rounding"`
+	// AdjustmentTolerance is how far, as a fraction of the quote, the price of the actual
+	// ride can drift before the final fare is adjusted. 0.1 keeps the quote within 10%.
+	AdjustmentTolerance float64          `json:"adjustmentTolerance" yaml:"adjustmentTolerance"`
+	Packages            []PackagePricing `json:"packages" yaml:"packages"`
+}
+
+// DefaultConfig is used when no pricing file is configured.
+func DefaultConfig() Config {
+	rates := func(slug string, base, minimum float64) PackagePricing {
+		return PackagePricing{
+			Slug:             slug,
+			BaseFareCents:    base,
+			PerKmCents:       150,
+			PerMinuteCents:   25,
+			MinimumFareCents: minimum,
+			BookingFeeCents:  100,
+		}
+	}
+
+	return Config{
+		Rounding:            Rounding{Mode: RoundNearest, IncrementCents: 1},
+		AdjustmentTolerance: 0.1,
+		Packages: []PackagePricing{
+			rates("suv", 200, 700),
+			rates("sedan", 350, 800),
+			rates("van", 400, 900),
+			rates("luxury", 1000, 2000),
+		},
+	}
+}
+
+// LoadConfig reads a pricing file, YAML or JSON depending on its extension.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read pricing config: %w", err)
+	}
+
+	var cfg Config
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &cfg)
+	case ".json":
+		err = json.Unmarshal(data, &cfg)
+	default:
+		return Config{}, fmt.Errorf("unsupported pricing config format %q", ext)
+	}
+
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to parse pricing config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func (c Config) Validate() error {
+	if len(c.Packages) == 0 {
+		return fmt.Errorf("pricing config has no packages")
+	}
+
+	switch c.Rounding.Mode {
+	case RoundUp, RoundDown, RoundNearest:
+	default:
+		return fmt.Errorf("unknown rounding mode %q", c.Rounding.Mode)
+	}
+
+	if c.Rounding.IncrementCents < 1 {
+		return fmt.Errorf("rounding increment must be at least 1 cent")
+	}
+
+	if c.AdjustmentTolerance < 0 {
+		return fmt.Errorf("adjustment tolerance can't be negative")
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range c.Packages {
+		if p.Slug == "" {
+			return fmt.Errorf("package without slug")
+		}
+
+		if seen[p.Slug] {
+			return fmt.Errorf("package %q defined twice", p.Slug)
+		}
+		seen[p.Slug] = true
+
+		if p.BaseFareCents < 0 || p.PerKmCents < 0 || p.PerMinuteCents < 0 || p.MinimumFareCents < 0 || p.BookingFeeCents < 0 {
+			return fmt.Errorf("package %q has a negative rate", p.Slug)
+		}
+	}
+
+	return nil
+}
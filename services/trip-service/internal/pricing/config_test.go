@@ -0,0 +1,69 @@
+package pricing
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSampleConfigMatchesDefaults(t *testing.T) {
+	cfg, err := LoadConfig("../../config/pricing.yaml")
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	if !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Fatalf("config/pricing.yaml = %+v, want the defaults %+v", cfg, DefaultConfig())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	sedan := PackagePricing{Slug: "sedan", BaseFareCents: 350}
+	rounding := Rounding{Mode: RoundNearest, IncrementCents: 1}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "defaults", cfg: DefaultConfig()},
+		{name: "no packages", cfg: Config{Rounding: rounding}, wantErr: true},
+		{
+			name:    "unknown rounding mode",
+			cfg:     Config{Rounding: Rounding{Mode: "bankers", IncrementCents: 1}, Packages: []PackagePricing{sedan}},
+			wantErr: true,
+		},
+		{
+			name:    "zero increment",
+			cfg:     Config{Rounding: Rounding{Mode: RoundUp}, Packages: []PackagePricing{sedan}},
+			wantErr: true,
+		},
+		{
+			name:    "negative tolerance",
+			cfg:     Config{Rounding: rounding, AdjustmentTolerance: -0.1, Packages: []PackagePricing{sedan}},
+			wantErr: true,
+		},
+		{
+			name:    "package without slug",
+			cfg:     Config{Rounding: rounding, Packages: []PackagePricing{{BaseFareCents: 350}}},
+			wantErr: true,
+		},
+		{
+			name:    "package twice",
+			cfg:     Config{Rounding: rounding, Packages: []PackagePricing{sedan, sedan}},
+			wantErr: true,
+		},
+		{
+			name:    "negative rate",
+			cfg:     Config{Rounding: rounding, Packages: []PackagePricing{{Slug: "sedan", PerKmCents: -1}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Package pricing turns a route into a price for every car package.
+*/
+package pricing
+
+import (
+	"fmt"
+	"math"
+
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+)
+
+// Quote is the price of a route for one package.
+type Quote struct {
+	PackageSlug     string
+	TotalCents      int64
+	SurgeMultiplier float64
+}
+
+// SurgeFunc returns the surge multiplier of a package, nil means no surge.
+type SurgeFunc func(packageSlug string) float64
+
+type Engine struct {
+	cfg Config
+}
+
+func NewEngine(cfg Config) (*Engine, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Engine{cfg: cfg}, nil
+}
+
+// Quote prices the route for every package, in the order of the config.
+func (e *Engine) Quote(route *tripTypes.OsrmApiResponse, surge SurgeFunc) ([]Quote, error) {
+	if route == nil || len(route.Routes) == 0 {
+		return nil, fmt.Errorf("route is required to price a trip")
+	}
+
+	// OSRM gives meters and seconds
+	distanceKm := route.Routes[0].Distance / 1000
+	durationMinutes := route.Routes[0].Duration / 60
+
+	quotes := make([]Quote, len(e.cfg.Packages))
+	for i, p := range e.cfg.Packages {
+		multiplier := 1.0
+		if surge != nil {
+			multiplier = math.Max(surge(p.Slug), 1)
+		}
+
+		quotes[i] = Quote{
+			PackageSlug:     p.Slug,
+			TotalCents:      e.price(p, distanceKm, durationMinutes, multiplier),
+			SurgeMultiplier: multiplier,
+		}
+	}
+
+	return quotes, nil
+}
+
+// Ride is what a trip actually took, or was expected to take.
+type Ride struct {
+	DistanceMeters  float64
+	DurationSeconds float64
+}
+
+// Adjustment changes the quoted price once the ride is over, negative amounts are refunds.
+type Adjustment struct {
+	Reason      string
+	AmountCents int64
+}
+
+const AdjustmentRouteChanged = "route_changed"
+
+// Settle reprices the ride that actually happened with the surge of the quote. Small
+// differences are absorbed, beyond the tolerance the rider pays (or gets back) the difference.
+func (e *Engine) Settle(packageSlug string, quotedCents int64, surge float64, actual Ride) ([]Adjustment, error) {
+	p, ok := e.pkg(packageSlug)
+	if !ok {
+		return nil, fmt.Errorf("unknown package %q", packageSlug)
+	}
+
+	actualCents := e.price(p, actual.DistanceMeters/1000, actual.DurationSeconds/60, math.Max(surge, 1))
+
+	diff := actualCents - quotedCents
+	if math.Abs(float64(diff)) <= e.cfg.AdjustmentTolerance*float64(quotedCents) {
+		return nil, nil
+	}
+
+	return []Adjustment{{Reason: AdjustmentRouteChanged, AmountCents: diff}}, nil
+}
+
+func (e *Engine) pkg(slug string) (PackagePricing, bool) {
+	for _, p := range e.cfg.Packages {
+		if p.Slug == slug {
+			return p, true
+		}
+	}
+
+	return PackagePricing{}, false
+}
+
+// price applies the minimum fare and the surge to the ride itself, the booking fee comes on top.
+func (e *Engine) price(p PackagePricing, distanceKm, durationMinutes, surge float64) int64 {
+	ride := p.BaseFareCents + p.PerKmCents*distanceKm + p.PerMinuteCents*durationMinutes
+	ride = math.Max(ride, p.MinimumFareCents) * surge
+
+	return e.round(ride + p.BookingFeeCents)
+}
+
+func (e *Engine) round(cents float64) int64 {
+	increment := float64(e.cfg.Rounding.IncrementCents)
+	steps := cents / increment
+
+	switch e.cfg.Rounding.Mode {
+	case RoundUp:
+		steps = math.Ceil(steps)
+	case RoundDown:
+		steps = math.Floor(steps)
+	default:
+		steps = math.Round(steps)
+	}
+
+	return int64(steps) * e.cfg.Rounding.IncrementCents
+}
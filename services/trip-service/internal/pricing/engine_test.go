@@ -0,0 +1,206 @@
+package pricing
+
+import (
+	"reflect"
+	"testing"
+
+	tripTypes "ride-sharing/services/trip-service/pkg/types"
+)
+
+func route(distanceMeters, durationSeconds float64) *tripTypes.OsrmApiResponse {
+	return &tripTypes.OsrmApiResponse{
+		Routes: []tripTypes.OsrmRoute{{Distance: distanceMeters, Duration: durationSeconds}},
+	}
+}
+
+func newEngine(t *testing.T, cfg Config) *Engine {
+	t.Helper()
+
+	engine, err := NewEngine(cfg)
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+
+	return engine
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		name  string
+		route *tripTypes.OsrmApiResponse
+		surge SurgeFunc
+		// wantSedan is the price of the sedan, 350 base, 150/km, 25/min, 800 minimum and 100 booking fee
+		wantSedan      int64
+		wantMultiplier float64
+	}{
+		{
+			name:           "distance and duration",
+			route:          route(10_000, 20*60),
+			wantSedan:      2450,
+			wantMultiplier: 1,
+		},
+		{
+			name:           "short ride pays the minimum fare",
+			route:          route(1_000, 2*60),
+			wantSedan:      900,
+			wantMultiplier: 1,
+		},
+		{
+			name:           "surge applies before the booking fee",
+			route:          route(10_000, 20*60),
+			surge:          func(string) float64 { return 1.5 },
+			wantSedan:      3625,
+			wantMultiplier: 1.5,
+		},
+		{
+			name:           "surge never discounts",
+			route:          route(10_000, 20*60),
+			surge:          func(string) float64 { return 0.5 },
+			wantSedan:      2450,
+			wantMultiplier: 1,
+		},
+	}
+
+	engine := newEngine(t, DefaultConfig())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quotes, err := engine.Quote(tt.route, tt.surge)
+			if err != nil {
+				t.Fatalf("Quote: %v", err)
+			}
+
+			if len(quotes) != len(DefaultConfig().Packages) {
+				t.Fatalf("got %d quotes, want one per package", len(quotes))
+			}
+
+			for _, q := range quotes {
+				if q.PackageSlug != "sedan" {
+					continue
+				}
+
+				if q.TotalCents != tt.wantSedan || q.SurgeMultiplier != tt.wantMultiplier {
+					t.Fatalf("sedan quote = %+v, want %d cents at x%v", q, tt.wantSedan, tt.wantMultiplier)
+				}
+
+				return
+			}
+
+			t.Fatal("no sedan quote")
+		})
+	}
+}
+
+func TestQuoteWithoutRoute(t *testing.T) {
+	engine := newEngine(t, DefaultConfig())
+
+	for _, r := range []*tripTypes.OsrmApiResponse{nil, {}} {
+		if _, err := engine.Quote(r, nil); err == nil {
+			t.Fatalf("Quote(%+v) succeeded", r)
+		}
+	}
+}
+
+func TestRounding(t *testing.T) {
+	tests := []struct {
+		mode RoundingMode
+		want int64
+	}{
+		// 350 + 1.22km * 150 + 100 = 633 cents
+		{mode: RoundNearest, want: 635},
+		{mode: RoundUp, want: 635},
+		{mode: RoundDown, want: 630},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			engine := newEngine(t, Config{
+				Rounding: Rounding{Mode: tt.mode, IncrementCents: 5},
+				Packages: []PackagePricing{{Slug: "sedan", BaseFareCents: 350, PerKmCents: 150, BookingFeeCents: 100}},
+			})
+
+			quotes, err := engine.Quote(route(1_220, 0), nil)
+			if err != nil {
+				t.Fatalf("Quote: %v", err)
+			}
+
+			if quotes[0].TotalCents != tt.want {
+				t.Fatalf("TotalCents = %d, want %d", quotes[0].TotalCents, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettle(t *testing.T) {
+	tests := []struct {
+		name    string
+		pkg     string
+		quoted  int64
+		surge   float64
+		actual  Ride
+		want    []Adjustment
+		wantErr bool
+	}{
+		{
+			name:   "ride as quoted",
+			pkg:    "sedan",
+			quoted: 2450,
+			surge:  1,
+			actual: Ride{DistanceMeters: 10_000, DurationSeconds: 20 * 60},
+		},
+		{
+			name:   "small detour is absorbed",
+			pkg:    "sedan",
+			quoted: 2450,
+			surge:  1,
+			// 2525 cents, within 10% of the quote
+			actual: Ride{DistanceMeters: 10_500, DurationSeconds: 20 * 60},
+		},
+		{
+			name:   "longer ride is charged",
+			pkg:    "sedan",
+			quoted: 2450,
+			surge:  1,
+			actual: Ride{DistanceMeters: 15_000, DurationSeconds: 30 * 60},
+			want:   []Adjustment{{Reason: AdjustmentRouteChanged, AmountCents: 1000}},
+		},
+		{
+			name:   "shorter ride is refunded down to the minimum fare",
+			pkg:    "sedan",
+			quoted: 2450,
+			surge:  1,
+			actual: Ride{DistanceMeters: 2_000, DurationSeconds: 5 * 60},
+			want:   []Adjustment{{Reason: AdjustmentRouteChanged, AmountCents: -1550}},
+		},
+		{
+			name:   "surge of the quote is kept",
+			pkg:    "sedan",
+			quoted: 3625,
+			surge:  1.5,
+			actual: Ride{DistanceMeters: 10_000, DurationSeconds: 20 * 60},
+		},
+		{
+			name:    "unknown package",
+			pkg:     "rickshaw",
+			quoted:  2450,
+			surge:   1,
+			actual:  Ride{DistanceMeters: 10_000, DurationSeconds: 20 * 60},
+			wantErr: true,
+		},
+	}
+
+	engine := newEngine(t, DefaultConfig())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := engine.Settle(tt.pkg, tt.quoted, tt.surge, tt.actual)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Settle error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Settle = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
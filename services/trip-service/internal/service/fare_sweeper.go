@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunFareSweeper deletes the expired fares every interval until ctx is done. Fares are kept
+// for grace after they expire, so a late booking is told the fare expired rather than not found.
+// Trips keep their own copy of the fare, deleting it doesn't affect them.
+func (s *Service) RunFareSweeper(ctx context.Context, interval, grace time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := s.repo.DeleteExpiredRideFares(ctx, time.Now().Add(-grace))
+		if err != nil {
+			log.Printf("Failed to delete expired ride fares: %v", err)
+			continue
+		}
+
+		if deleted > 0 {
+			log.Printf("Deleted %d expired ride fares", deleted)
+		}
+	}
+}
@@ -2,14 +2,16 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"ride-sharing/services/trip-service/internal/domain"
+	"ride-sharing/services/trip-service/internal/pricing"
+	"ride-sharing/services/trip-service/internal/surge"
+	pbd "ride-sharing/shared/proto/driver"
 	"ride-sharing/shared/proto/trip"
 	"ride-sharing/shared/types"
+	"time"
 
 	tripTypes "ride-sharing/services/trip-service/pkg/types"
 
@@ -17,97 +19,321 @@ import (
 )
 
 type Service struct {
-	repo domain.TripRepository
+	repo    domain.TripRepository
+	routes  domain.RouteProvider
+	pricing *pricing.Engine
+	// surge is optional, without it fares never surge
+	surge *surge.Tracker
+	cfg   Config
 }
 
-func NewService(repo domain.TripRepository) *Service {
+type Config struct {
+	// FareTTL is how long a previewed fare can be booked
+	FareTTL      time.Duration
+	Cancellation domain.CancellationPolicy
+}
+
+func DefaultConfig() Config {
+	return Config{
+		FareTTL:      domain.DefaultRideFareTTL,
+		Cancellation: domain.DefaultCancellationPolicy(),
+	}
+}
+
+func NewService(repo domain.TripRepository, routes domain.RouteProvider, pricing *pricing.Engine, surge *surge.Tracker, cfg Config) *Service {
+	if cfg.FareTTL <= 0 {
+		cfg.FareTTL = domain.DefaultRideFareTTL
+	}
+
 	return &Service{
-		repo: repo,
+		repo:    repo,
+		routes:  routes,
+		pricing: pricing,
+		surge:   surge,
+		cfg:     cfg,
 	}
 }
 
+// CreateTrip books the fare, a fare can only be booked once and before it expires.
 func (s *Service) CreateTrip(ctx context.Context, fare *domain.RideFareModel) (*domain.TripModel, error) {
 
+	now := time.Now()
+
+	fare, err := s.repo.ConsumeRideFare(ctx, fare.ID.Hex(), now)
+	if err != nil {
+		return nil, err
+	}
+
 	t := &domain.TripModel{
-		ID:       primitive.NewObjectID(),
-		UserID:   fare.UserID,
-		Status:   "Pending",
-		RideFare: fare,
-		Driver:   &trip.TripDriver{},
+		ID:     primitive.NewObjectID(),
+		UserID: fare.UserID,
+		Status: domain.TripStatusPending,
+		StatusHistory: []domain.TripStatusChange{
+			{Status: domain.TripStatusPending, At: now},
+		},
+		RideFare:  fare,
+		Driver:    &trip.TripDriver{},
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
-	return s.repo.CreateTrip(ctx, t)
-}
+	created, err := s.repo.CreateTrip(ctx, t)
+	if err != nil {
+		// the rider can book the fare again rather than preview a new one
+		s.releaseFare(ctx, fare.ID.Hex())
+		return nil, err
+	}
 
-func (s *Service) GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error) {
-	url := fmt.Sprintf("http://router.project-osrm.org/route/v1/driving/%f,%f;%f,%f?overview=full&geometries=geojson", pickup.Longitude, pickup.Latitude, destination.Longitude, destination.Latitude)
+	if lat, lng, ok := fare.Route.Pickup(); ok && s.surge != nil {
+		s.surge.RecordTripCreated(lat, lng, fare.PackageSlug)
+	}
 
-	fmt.Println("---------------------")
-	log.Println(url, "url")
-	fmt.Println("---------------------")
-	resp, err := http.Get(url)
+	return created, nil
+}
 
+// AbortTrip cancels a trip nobody is going to dispatch and gives its fare back, so the
+// rider can book it again.
+func (s *Service) AbortTrip(ctx context.Context, tripID string) error {
+	t, err := s.CancelTrip(ctx, tripID, domain.CancellationRequest{
+		Actor:  domain.CancelledBySystem,
+		Reason: domain.CancelReasonDispatchFailed,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch the route from OSRM %v", err)
+		return err
 	}
 
-	log.Println(resp, "<-respo")
-
-	defer resp.Body.Close()
+	s.releaseFare(ctx, t.RideFare.ID.Hex())
 
-	body, err := io.ReadAll(resp.Body)
+	return nil
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to read the response %v", err)
+func (s *Service) releaseFare(ctx context.Context, fareID string) {
+	if err := s.repo.ReleaseRideFare(ctx, fareID); err != nil {
+		log.Printf("Failed to release ride fare %s: %v", fareID, err)
 	}
+}
 
-	var routeResp tripTypes.OsrmApiResponse
-	if err := json.Unmarshal(body, &routeResp); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
+func (s *Service) GetTripByID(ctx context.Context, tripID string) (*domain.TripModel, error) {
+	return s.repo.GetTripByID(ctx, tripID)
+}
+
+func (s *Service) TransitionTrip(ctx context.Context, tripID string, next domain.TripStatus) (*domain.TripModel, error) {
+	return s.updateTrip(ctx, tripID, func(t *domain.TripModel) error {
+		return t.Transition(next, time.Now())
+	})
+}
+
+// AssignDriver gives the trip to the driver who accepted the offer. Accepting again returns
+// the trip unchanged, so a failed notification can be sent again.
+func (s *Service) AssignDriver(ctx context.Context, tripID, riderID string, driver *pbd.Driver) (*domain.TripModel, error) {
+	if driver == nil || driver.Id == "" {
+		return nil, fmt.Errorf("driver is required")
 	}
 
-	return &routeResp, nil
+	return s.updateTrip(ctx, tripID, func(t *domain.TripModel) error {
+		if t.UserID != riderID {
+			return domain.ErrTripOwnerMismatch
+		}
+
+		err := t.Accept(driver, time.Now())
+		if errors.Is(err, domain.ErrAlreadyAccepted) {
+			return errNothingToUpdate
+		}
+
+		return err
+	})
+}
+
+// OfferTrip records which driver the dispatcher offered the trip to.
+func (s *Service) OfferTrip(ctx context.Context, tripID, driverID string) (*domain.TripModel, error) {
+	return s.updateTrip(ctx, tripID, func(t *domain.TripModel) error {
+		if t.Status == domain.TripStatusDriverOffered && t.OfferedDriverID == driverID {
+			return errNothingToUpdate
+		}
+
+		return t.Offer(driverID, time.Now())
+	})
 }
 
-func (s *Service) EstimatePackagesPriceWithRoute(route *tripTypes.OsrmApiResponse) []*domain.RideFareModel {
-	baseFare := getBaseFares()
+// DeclineTrip puts a trip whose offer was declined back up for dispatch.
+func (s *Service) DeclineTrip(ctx context.Context, tripID, riderID, driverID string) (*domain.TripModel, error) {
+	return s.updateTrip(ctx, tripID, func(t *domain.TripModel) error {
+		if t.UserID != riderID {
+			return domain.ErrTripOwnerMismatch
+		}
 
-	estimatedFares := make([]*domain.RideFareModel, len(baseFare))
+		return t.Decline(driverID, time.Now())
+	})
+}
 
-	for i, f := range baseFare {
-		estimatedFares[i] = estimatedFareRoute(f, route)
-	}
+// CancelTrip cancels the trip on behalf of a rider, a driver or the system, see TripModel.Cancel for the rules.
+// The same cancellation made again returns the trip unchanged, so its event can be published again.
+func (s *Service) CancelTrip(ctx context.Context, tripID string, req domain.CancellationRequest) (*domain.TripModel, error) {
+	return s.updateTrip(ctx, tripID, func(t *domain.TripModel) error {
+		if c := t.Cancellation; t.Status == domain.TripStatusCancelled && c != nil &&
+			c.Actor == req.Actor && c.ActorID == req.ActorID && c.Reason == req.Reason {
+			return errNothingToUpdate
+		}
+
+		return t.Cancel(req, s.cfg.Cancellation, time.Now())
+	})
+}
+
+func (s *Service) ListRiderTrips(ctx context.Context, riderID string, statuses []domain.TripStatus, page domain.PageRequest) (*domain.TripPage, error) {
+	return s.repo.ListTrips(ctx, domain.TripFilter{UserID: riderID, Statuses: statuses}, page)
+}
+
+func (s *Service) ListDriverTrips(ctx context.Context, driverID string, statuses []domain.TripStatus, page domain.PageRequest) (*domain.TripPage, error) {
+	return s.repo.ListTrips(ctx, domain.TripFilter{DriverID: driverID, Statuses: statuses}, page)
+}
+
+// NoDriverFound closes a trip the dispatcher found no driver for.
+func (s *Service) NoDriverFound(ctx context.Context, tripID string) (*domain.TripModel, error) {
+	return s.updateTrip(ctx, tripID, func(t *domain.TripModel) error {
+		if t.Status == domain.TripStatusNoDriver {
+			return errNothingToUpdate
+		}
+
+		if err := t.Transition(domain.TripStatusNoDriver, time.Now()); err != nil {
+			return err
+		}
+
+		t.OfferedDriverID = ""
 
-	return estimatedFares
+		return nil
+	})
 }
 
-// Private method: estimatedFareRoute
-func estimatedFareRoute(f *domain.RideFareModel, route *tripTypes.OsrmApiResponse) *domain.RideFareModel {
-	// distance
-	// time
-	// car price
+// SetOffForPickup records that the assigned driver is driving to the pickup.
+func (s *Service) SetOffForPickup(ctx context.Context, tripID, driverID string) (*domain.TripModel, error) {
+	return s.updateTrip(ctx, tripID, func(t *domain.TripModel) error {
+		return t.SetOff(driverID, time.Now())
+	})
+}
 
-	pricingCfg := tripTypes.DefaultPricingConfig()
-	carPackagePrice := f.TotalPriceCents
+// StartTrip records that the assigned driver picked the rider up. Starting again returns the
+// trip unchanged, so its event can be published again.
+func (s *Service) StartTrip(ctx context.Context, tripID, driverID string) (*domain.TripModel, error) {
+	return s.updateTrip(ctx, tripID, func(t *domain.TripModel) error {
+		if t.Status == domain.TripStatusInProgress && t.Driver.GetId() == driverID {
+			return errNothingToUpdate
+		}
 
-	distanceKm := route.Routes[0].Distance
-	durationInMinutes := route.Routes[0].Duration
+		return t.Start(driverID, time.Now())
+	})
+}
 
-	distanceFare := distanceKm * pricingCfg.PricePerUnitOfDistance
+// CompleteTrip drops the rider off and settles the final fare from the quote and the actual ride.
+// Without a reported distance the planned route is used. Completing again returns the trip
+// unchanged, the fare is only settled once.
+func (s *Service) CompleteTrip(ctx context.Context, tripID, driverID string, distanceMeters float64) (*domain.TripModel, error) {
+	return s.updateTrip(ctx, tripID, func(t *domain.TripModel) error {
+		if t.Status == domain.TripStatusCompleted && t.Driver.GetId() == driverID {
+			return errNothingToUpdate
+		}
 
-	timeFare := durationInMinutes * pricingCfg.PricingPerMinute
+		fare := t.RideFare
 
-	totalPrice := carPackagePrice + distanceFare + timeFare
+		if distanceMeters <= 0 && fare.Route != nil && len(fare.Route.Routes) > 0 {
+			distanceMeters = fare.Route.Routes[0].Distance
+		}
+
+		if err := t.Complete(driverID, distanceMeters, time.Now()); err != nil {
+			return err
+		}
 
-	return &domain.RideFareModel{
-		PackageSlug:     f.PackageSlug,
-		TotalPriceCents: totalPrice,
+		adjustments, err := s.pricing.Settle(fare.PackageSlug, fare.TotalPriceCents, fare.SurgeMultiplier, pricing.Ride{
+			DistanceMeters:  distanceMeters,
+			DurationSeconds: t.RideDuration().Seconds(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to settle the fare: %w", err)
+		}
+
+		fareAdjustments := make([]domain.FareAdjustment, len(adjustments))
+		for i, a := range adjustments {
+			fareAdjustments[i] = domain.FareAdjustment{Reason: a.Reason, AmountCents: a.AmountCents}
+		}
+
+		t.FinalFare = domain.NewFinalFare(fare.TotalPriceCents, fareAdjustments)
+
+		return nil
+	})
+}
+
+// errNothingToUpdate lets an updateTrip mutation return the trip without saving it.
+var errNothingToUpdate = errors.New("nothing to update")
+
+const maxUpdateAttempts = 3
+
+// updateTrip reads the trip, applies mutate and saves it, starting over from a
+// fresh copy when someone else updated the trip in the meantime.
+func (s *Service) updateTrip(ctx context.Context, tripID string, mutate func(*domain.TripModel) error) (*domain.TripModel, error) {
+	var err error
+
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		var t *domain.TripModel
+		t, err = s.repo.GetTripByID(ctx, tripID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = mutate(t); err != nil {
+			if errors.Is(err, errNothingToUpdate) {
+				return t, nil
+			}
+
+			return nil, err
+		}
+
+		err = s.repo.UpdateTrip(ctx, t)
+		if err == nil {
+			return t, nil
+		}
+
+		if !errors.Is(err, domain.ErrTripVersionConflict) {
+			return nil, fmt.Errorf("failed to update trip: %w", err)
+		}
 	}
 
+	return nil, fmt.Errorf("failed to update trip: %w", err)
+}
+
+func (s *Service) GetRoute(ctx context.Context, pickup, destination *types.Coordinate) (*tripTypes.OsrmApiResponse, error) {
+	return s.routes.GetRoute(ctx, pickup, destination)
+}
+
+// EstimatePackagesPriceWithRoute prices the route for every package, including the current
+// surge around the pickup. The multiplier is stored with the fare so it can't change afterwards.
+func (s *Service) EstimatePackagesPriceWithRoute(route *tripTypes.OsrmApiResponse, pickup *types.Coordinate) ([]*domain.RideFareModel, error) {
+	var surgeFn pricing.SurgeFunc
+	if s.surge != nil && pickup != nil {
+		surgeFn = func(packageSlug string) float64 {
+			return s.surge.Multiplier(pickup.Latitude, pickup.Longitude, packageSlug)
+		}
+	}
+
+	quotes, err := s.pricing.Quote(route, surgeFn)
+	if err != nil {
+		return nil, err
+	}
+
+	estimatedFares := make([]*domain.RideFareModel, len(quotes))
+
+	for i, q := range quotes {
+		estimatedFares[i] = &domain.RideFareModel{
+			PackageSlug:     q.PackageSlug,
+			TotalPriceCents: q.TotalCents,
+			SurgeMultiplier: q.SurgeMultiplier,
+		}
+	}
+
+	return estimatedFares, nil
 }
 
 func (s *Service) GenerateTripFares(ctx context.Context, rideFares []*domain.RideFareModel, userID string, route *tripTypes.OsrmApiResponse) ([]*domain.RideFareModel, error) {
 	fares := make([]*domain.RideFareModel, len(rideFares))
+	expiresAt := time.Now().Add(s.cfg.FareTTL)
 
 	for i, f := range rideFares {
 		id := primitive.NewObjectID()
@@ -116,8 +342,10 @@ func (s *Service) GenerateTripFares(ctx context.Context, rideFares []*domain.Rid
 			UserID:          userID,
 			ID:              id,
 			TotalPriceCents: f.TotalPriceCents,
+			SurgeMultiplier: f.SurgeMultiplier,
 			PackageSlug:     f.PackageSlug,
 			Route:           route,
+			ExpiresAt:       expiresAt,
 		}
 
 		if err := s.repo.SaveRideFare(ctx, fare); err != nil {
@@ -130,24 +358,6 @@ func (s *Service) GenerateTripFares(ctx context.Context, rideFares []*domain.Rid
 	return fares, nil
 }
 
-func getBaseFares() []*domain.RideFareModel {
-	return []*domain.RideFareModel{
-		{
-			PackageSlug:     "suv",
-			TotalPriceCents: 200,
-		},
-		{
-			PackageSlug: "sedan", TotalPriceCents: 350,
-		},
-		{
-			PackageSlug: "van", TotalPriceCents: 400,
-		},
-		{
-			PackageSlug: "luxury", TotalPriceCents: 1000,
-		},
-	}
-}
-
 func (s *Service) GetAndValidateFare(ctx context.Context, fareID, userID string) (*domain.RideFareModel, error) {
 	fare, err := s.repo.GetRideFareByID(ctx, fareID)
 
@@ -155,12 +365,12 @@ func (s *Service) GetAndValidateFare(ctx context.Context, fareID, userID string)
 		return nil, fmt.Errorf("failed to get trip fare: %w", err)
 	}
 
-	if fare == nil {
-		return nil, fmt.Errorf("fare not found")
+	if userID != fare.UserID {
+		return nil, fmt.Errorf("%w: %s", domain.ErrRideFareOwnerMismatch, fareID)
 	}
 
-	if userID != fare.UserID {
-		return nil, fmt.Errorf("fare does not belong to the user")
+	if err := fare.Usable(time.Now()); err != nil {
+		return nil, err
 	}
 
 	return fare, nil
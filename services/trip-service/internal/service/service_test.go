@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ride-sharing/services/trip-service/internal/domain"
+	"ride-sharing/services/trip-service/internal/infrastructure/repository"
+	"ride-sharing/services/trip-service/internal/pricing"
+	pbd "ride-sharing/shared/proto/driver"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// newAssignedTrip returns a trip of rider u1 accepted by driver d1.
+func newAssignedTrip(t *testing.T) (*Service, string) {
+	t.Helper()
+
+	engine, err := pricing.NewEngine(pricing.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+
+	repo := repository.NewInmemRepository()
+	s := NewService(repo, nil, engine, nil, DefaultConfig())
+	ctx := context.Background()
+
+	fare := &domain.RideFareModel{
+		ID:              primitive.NewObjectID(),
+		UserID:          "u1",
+		PackageSlug:     "sedan",
+		TotalPriceCents: 1250,
+		ExpiresAt:       time.Now().Add(time.Minute),
+	}
+	if err := repo.SaveRideFare(ctx, fare); err != nil {
+		t.Fatalf("SaveRideFare: %v", err)
+	}
+
+	trip, err := s.CreateTrip(ctx, fare)
+	if err != nil {
+		t.Fatalf("CreateTrip: %v", err)
+	}
+
+	tripID := trip.ID.Hex()
+
+	if _, err := s.OfferTrip(ctx, tripID, "d1"); err != nil {
+		t.Fatalf("OfferTrip: %v", err)
+	}
+
+	if _, err := s.AssignDriver(ctx, tripID, "u1", &pbd.Driver{Id: "d1"}); err != nil {
+		t.Fatalf("AssignDriver: %v", err)
+	}
+
+	return s, tripID
+}
+
+// TestRepeatedRequests checks that a request retried after its event failed to publish
+// gets the trip back, rather than an error, and does not change it again.
+func TestRepeatedRequests(t *testing.T) {
+	riderCancel := domain.CancellationRequest{Actor: domain.CancelledByRider, ActorID: "u1", Reason: domain.CancelReasonChangedMind}
+
+	tests := []struct {
+		name    string
+		request func(ctx context.Context, s *Service, tripID string) (*domain.TripModel, error)
+		// retry is the request made again, request itself when nil
+		retry      func(ctx context.Context, s *Service, tripID string) (*domain.TripModel, error)
+		wantErr    error
+		wantStatus domain.TripStatus
+	}{
+		{
+			name: "same cancellation",
+			request: func(ctx context.Context, s *Service, tripID string) (*domain.TripModel, error) {
+				return s.CancelTrip(ctx, tripID, riderCancel)
+			},
+			wantStatus: domain.TripStatusCancelled,
+		},
+		{
+			name: "same start",
+			request: func(ctx context.Context, s *Service, tripID string) (*domain.TripModel, error) {
+				return s.StartTrip(ctx, tripID, "d1")
+			},
+			wantStatus: domain.TripStatusInProgress,
+		},
+		{
+			name: "start by another driver",
+			request: func(ctx context.Context, s *Service, tripID string) (*domain.TripModel, error) {
+				return s.StartTrip(ctx, tripID, "d1")
+			},
+			retry: func(ctx context.Context, s *Service, tripID string) (*domain.TripModel, error) {
+				return s.StartTrip(ctx, tripID, "d2")
+			},
+			wantErr:    domain.ErrTripDriverMismatch,
+			wantStatus: domain.TripStatusInProgress,
+		},
+		{
+			name: "same completion",
+			request: func(ctx context.Context, s *Service, tripID string) (*domain.TripModel, error) {
+				if _, err := s.StartTrip(ctx, tripID, "d1"); err != nil {
+					return nil, err
+				}
+
+				return s.CompleteTrip(ctx, tripID, "d1", 5000)
+			},
+			retry: func(ctx context.Context, s *Service, tripID string) (*domain.TripModel, error) {
+				return s.CompleteTrip(ctx, tripID, "d1", 5000)
+			},
+			wantStatus: domain.TripStatusCompleted,
+		},
+		{
+			name: "another cancellation",
+			request: func(ctx context.Context, s *Service, tripID string) (*domain.TripModel, error) {
+				return s.CancelTrip(ctx, tripID, riderCancel)
+			},
+			retry: func(ctx context.Context, s *Service, tripID string) (*domain.TripModel, error) {
+				return s.CancelTrip(ctx, tripID, domain.CancellationRequest{
+					Actor:   domain.CancelledByDriver,
+					ActorID: "d1",
+					Reason:  domain.CancelReasonVehicleIssue,
+				})
+			},
+			wantErr:    domain.ErrInvalidTripTransition,
+			wantStatus: domain.TripStatusCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, tripID := newAssignedTrip(t)
+			ctx := context.Background()
+
+			first, err := tt.request(ctx, s, tripID)
+			if err != nil {
+				t.Fatalf("first request: %v", err)
+			}
+
+			retry := tt.retry
+			if retry == nil {
+				retry = tt.request
+			}
+
+			again, err := retry(ctx, s, tripID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("retry error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("retry: %v", err)
+			} else if again.Version != first.Version || again.Status != tt.wantStatus {
+				t.Fatalf("retry returned version %d in %s, want version %d in %s", again.Version, again.Status, first.Version, tt.wantStatus)
+			}
+
+			stored, err := s.GetTripByID(ctx, tripID)
+			if err != nil {
+				t.Fatalf("GetTripByID: %v", err)
+			}
+
+			if stored.Version != first.Version || stored.Status != tt.wantStatus {
+				t.Fatalf("stored trip is version %d in %s, want version %d in %s", stored.Version, stored.Status, first.Version, tt.wantStatus)
+			}
+		})
+	}
+}
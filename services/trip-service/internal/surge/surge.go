@@ -0,0 +1,172 @@
+/*
+Package surge raises prices where riders create more trips than there are drivers around.
+
+Demand is the number of trips created in a geohash cell during the last Window,
+supply comes from the snapshots the driver service broadcasts.
+*/
+package surge
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/mmcloughlin/geohash"
+)
+
+type Config struct {
+	// Precision of the geohash cells, 5 is about 5km x 5km
+	Precision uint
+	// Window is how far back trips are counted, and how long a supply snapshot is trusted
+	Window time.Duration
+	// Sensitivity is how much the multiplier grows per extra trip per driver
+	Sensitivity   float64
+	MaxMultiplier float64
+	// Step rounds the multiplier, riders see 1.3x rather than 1.2837x
+	Step float64
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Precision:     5,
+		Window:        10 * time.Minute,
+		Sensitivity:   0.5,
+		MaxMultiplier: 2.5,
+		Step:          0.1,
+	}
+}
+
+type cellKey struct {
+	cell        string
+	packageSlug string
+}
+
+// CellSupply is the number of drivers of a package in a geohash cell.
+type CellSupply struct {
+	Geohash     string
+	PackageSlug string
+	Drivers     int
+}
+
+type Tracker struct {
+	cfg Config
+
+	demand    map[cellKey][]time.Time
+	supply    map[cellKey]int
+	supplyAt  time.Time
+	hasSupply bool
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+func NewTracker(cfg Config) *Tracker {
+	return &Tracker{
+		cfg:    cfg,
+		demand: make(map[cellKey][]time.Time),
+		supply: make(map[cellKey]int),
+		now:    time.Now,
+	}
+}
+
+func (t *Tracker) key(lat, lng float64, packageSlug string) cellKey {
+	return cellKey{
+		cell:        geohash.EncodeWithPrecision(lat, lng, t.cfg.Precision),
+		packageSlug: packageSlug,
+	}
+}
+
+// RecordTripCreated counts a trip towards the demand of its pickup cell.
+func (t *Tracker) RecordTripCreated(lat, lng float64, packageSlug string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	k := t.key(lat, lng, packageSlug)
+	t.demand[k] = append(t.prune(t.demand[k]), t.now())
+}
+
+// pruneCell drops the trips of the cell older than the window, and the cell once it has none left.
+// Must be called with t.mu held.
+func (t *Tracker) pruneCell(k cellKey) int {
+	times := t.prune(t.demand[k])
+	if len(times) == 0 {
+		delete(t.demand, k)
+		return 0
+	}
+
+	t.demand[k] = times
+
+	return len(times)
+}
+
+// UpdateSupply replaces the supply with a new snapshot taken at the given precision.
+// Finer snapshots are summed up into our cells, coarser ones can't be used.
+func (t *Tracker) UpdateSupply(precision uint, cells []CellSupply, at time.Time) {
+	if precision < t.cfg.Precision {
+		log.Printf("Ignoring driver supply with precision %d, need at least %d", precision, t.cfg.Precision)
+		return
+	}
+
+	supply := make(map[cellKey]int, len(cells))
+	for _, c := range cells {
+		cell := c.Geohash
+		if uint(len(cell)) > t.cfg.Precision {
+			cell = cell[:t.cfg.Precision]
+		}
+
+		supply[cellKey{cell: cell, packageSlug: c.PackageSlug}] += c.Drivers
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.supply = supply
+	t.supplyAt = at
+	t.hasSupply = true
+
+	// snapshots come regularly, a good time to forget the cells nobody asked about lately
+	for k := range t.demand {
+		t.pruneCell(k)
+	}
+}
+
+// Multiplier returns the surge multiplier for a pickup, 1 means no surge.
+// Without a recent supply snapshot we don't know how many drivers are around, so no surge either.
+func (t *Tracker) Multiplier(lat, lng float64, packageSlug string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.hasSupply || t.now().Sub(t.supplyAt) > t.cfg.Window {
+		return 1
+	}
+
+	k := t.key(lat, lng, packageSlug)
+
+	demand := float64(t.pruneCell(k))
+	drivers := math.Max(float64(t.supply[k]), 1)
+
+	ratio := demand / drivers
+	if ratio <= 1 {
+		return 1
+	}
+
+	multiplier := 1 + t.cfg.Sensitivity*(ratio-1)
+	if t.cfg.Step > 0 {
+		multiplier = math.Round(multiplier/t.cfg.Step) * t.cfg.Step
+	}
+
+	return math.Min(multiplier, t.cfg.MaxMultiplier)
+}
+
+// prune drops the trips older than the window. Must be called with t.mu held.
+func (t *Tracker) prune(times []time.Time) []time.Time {
+	cutoff := t.now().Add(-t.cfg.Window)
+
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+
+	return times[i:]
+}
@@ -0,0 +1,158 @@
+package surge
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/mmcloughlin/geohash"
+)
+
+const (
+	pickupLat = 52.5200
+	pickupLng = 13.4050
+)
+
+func TestMultiplier(t *testing.T) {
+	cell := geohash.EncodeWithPrecision(pickupLat, pickupLng, 5)
+	fineCell := geohash.EncodeWithPrecision(pickupLat, pickupLng, 6)
+
+	tests := []struct {
+		name string
+		// trips are created this long before the multiplier is asked for
+		trips []time.Duration
+		// otherTrips are created for another package
+		otherTrips []time.Duration
+		precision  uint
+		supply     []CellSupply
+		supplyAge  time.Duration
+		noSupply   bool
+		want       float64
+	}{
+		{
+			name:     "no supply snapshot",
+			trips:    []time.Duration{0, 0, 0, 0},
+			noSupply: true,
+			want:     1,
+		},
+		{
+			name:      "stale supply snapshot",
+			trips:     []time.Duration{0, 0, 0, 0},
+			precision: 5,
+			supply:    []CellSupply{{Geohash: cell, PackageSlug: "sedan", Drivers: 1}},
+			supplyAge: 11 * time.Minute,
+			want:      1,
+		},
+		{
+			name:      "as many drivers as trips",
+			trips:     []time.Duration{0, 0},
+			precision: 5,
+			supply:    []CellSupply{{Geohash: cell, PackageSlug: "sedan", Drivers: 2}},
+			want:      1,
+		},
+		{
+			name:      "twice as many trips as drivers",
+			trips:     []time.Duration{0, 0, 0, 0},
+			precision: 5,
+			supply:    []CellSupply{{Geohash: cell, PackageSlug: "sedan", Drivers: 2}},
+			want:      1.5,
+		},
+		{
+			name:      "rounded to the step",
+			trips:     []time.Duration{0, 0, 0, 0, 0},
+			precision: 5,
+			supply:    []CellSupply{{Geohash: cell, PackageSlug: "sedan", Drivers: 3}},
+			want:      1.3,
+		},
+		{
+			name:      "no driver counts as one",
+			trips:     []time.Duration{0, 0, 0},
+			precision: 5,
+			supply:    []CellSupply{{Geohash: cell, PackageSlug: "suv", Drivers: 5}},
+			want:      2,
+		},
+		{
+			name:      "capped",
+			trips:     []time.Duration{0, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+			precision: 5,
+			supply:    []CellSupply{{Geohash: cell, PackageSlug: "sedan", Drivers: 1}},
+			want:      2.5,
+		},
+		{
+			name:      "trips out of the window are forgotten",
+			trips:     []time.Duration{11 * time.Minute, 11 * time.Minute, 11 * time.Minute, 0},
+			precision: 5,
+			supply:    []CellSupply{{Geohash: cell, PackageSlug: "sedan", Drivers: 1}},
+			want:      1,
+		},
+		{
+			name:       "other packages don't count",
+			trips:      []time.Duration{0},
+			otherTrips: []time.Duration{0, 0, 0, 0},
+			precision:  5,
+			supply:     []CellSupply{{Geohash: cell, PackageSlug: "sedan", Drivers: 1}},
+			want:       1,
+		},
+		{
+			name:      "finer supply is summed up",
+			trips:     []time.Duration{0, 0, 0, 0},
+			precision: 6,
+			supply: []CellSupply{
+				{Geohash: fineCell, PackageSlug: "sedan", Drivers: 1},
+				{Geohash: cell + "z", PackageSlug: "sedan", Drivers: 1},
+			},
+			want: 1.5,
+		},
+		{
+			name:      "coarser supply is ignored",
+			trips:     []time.Duration{0, 0, 0, 0},
+			precision: 4,
+			supply:    []CellSupply{{Geohash: cell[:4], PackageSlug: "sedan", Drivers: 4}},
+			want:      1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			tracker := NewTracker(DefaultConfig())
+
+			record := func(ago []time.Duration, packageSlug string) {
+				for _, d := range ago {
+					tracker.now = func() time.Time { return now.Add(-d) }
+					tracker.RecordTripCreated(pickupLat, pickupLng, packageSlug)
+				}
+			}
+
+			record(tt.trips, "sedan")
+			record(tt.otherTrips, "suv")
+			tracker.now = func() time.Time { return now }
+
+			if !tt.noSupply {
+				tracker.UpdateSupply(tt.precision, tt.supply, now.Add(-tt.supplyAge))
+			}
+
+			got := tracker.Multiplier(pickupLat, pickupLng, "sedan")
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("Multiplier = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOldDemandIsForgotten(t *testing.T) {
+	now := time.Now()
+	tracker := NewTracker(DefaultConfig())
+
+	tracker.now = func() time.Time { return now }
+	tracker.RecordTripCreated(pickupLat, pickupLng, "sedan")
+	tracker.RecordTripCreated(pickupLat+1, pickupLng, "sedan")
+
+	// nobody asks about these cells again, the next snapshot drops them
+	tracker.now = func() time.Time { return now.Add(DefaultConfig().Window + time.Second) }
+	tracker.UpdateSupply(DefaultConfig().Precision, nil, tracker.now())
+
+	if len(tracker.demand) != 0 {
+		t.Fatalf("demand keeps %d cells, want none", len(tracker.demand))
+	}
+}
@@ -5,13 +5,19 @@ import (
 )
 
 type OsrmApiResponse struct {
-	Routes []struct {
-		Distance float64 `json:"distance"`
-		Duration float64 `json:"duration"`
-		Geometry struct {
-			Coordinates [][]float64 `json:"coordinates"`
-		} `json:"geometry"`
-	} `json:"routes"`
+	Code    string      `json:"code,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Routes  []OsrmRoute `json:"routes"`
+}
+
+// OsrmRoute is a single route, distance in meters and duration in seconds.
+type OsrmRoute struct {
+	Distance float64 `json:"distance"`
+	Duration float64 `json:"duration"`
+	Geometry struct {
+		// Coordinates are [longitude, latitude] pairs, as in GeoJSON
+		Coordinates [][]float64 `json:"coordinates"`
+	} `json:"geometry"`
 }
 
 func (o *OsrmApiResponse) ToProto() *pb.Route {
@@ -36,14 +42,16 @@ func (o *OsrmApiResponse) ToProto() *pb.Route {
 	}
 }
 
-type PricingConfig struct {
-	PricePerUnitOfDistance float64
-	PricingPerMinute       float64
-}
+// Pickup returns the first point of the route.
+func (o *OsrmApiResponse) Pickup() (lat, lng float64, ok bool) {
+	if o == nil || len(o.Routes) == 0 || len(o.Routes[0].Geometry.Coordinates) == 0 {
+		return 0, 0, false
+	}
 
-func DefaultPricingConfig() *PricingConfig {
-	return &PricingConfig{
-		PricePerUnitOfDistance: 1.5,
-		PricingPerMinute:       0.25,
+	first := o.Routes[0].Geometry.Coordinates[0]
+	if len(first) < 2 {
+		return 0, 0, false
 	}
+
+	return first[1], first[0], true
 }
@@ -1,9 +1,24 @@
 package contracts
 
-// AmqpMessage is the message structure for AMQP.
-type AmqpMessage struct {
-	OwnerID string `json:"ownerId"`
-	Data    []byte `json:"data"`
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventEnvelope is the message structure for AMQP, Data holds the payload of the Type.
+type EventEnvelope struct {
+	EventID string `json:"eventId"`
+	// Type is the routing key the event was published with
+	Type string `json:"type"`
+	// SchemaVersion of the payload, only bumped on breaking changes
+	SchemaVersion int       `json:"schemaVersion"`
+	OccurredAt    time.Time `json:"occurredAt"`
+	// CorrelationID is shared by every event following from the same request,
+	// CausationID is the event that was being handled when this one was published
+	CorrelationID string          `json:"correlationId"`
+	CausationID   string          `json:"causationId,omitempty"`
+	OwnerID       string          `json:"ownerId,omitempty"`
+	Data          json.RawMessage `json:"data"`
 }
 
 // Routing keys - using consistent event/command patterns
@@ -13,13 +28,27 @@ const (
 	TripEventDriverAssigned      = "trip.event.driver_assigned"
 	TripEventNoDriversFound      = "trip.event.no_drivers_found"
 	TripEventDriverNotInterested = "trip.event.driver_not_interested"
+	TripEventStarted             = "trip.event.started"
+	TripEventCancelled           = "trip.event.cancelled"
+	TripEventCompleted           = "trip.event.completed"
 
 	// Driver commands (driver.cmd.*)
 	DriverCmdTripRequest = "driver.cmd.trip_request"
 	DriverCmdTripAccept  = "driver.cmd.trip_accept"
 	DriverCmdTripDecline = "driver.cmd.trip_decline"
-	DriverCmdLocation    = "driver.cmd.location"
-	DriverCmdRegister    = "driver.cmd.register"
+	// DriverCmdTripCancelled tells a driver the trip they were offered or assigned is off
+	DriverCmdTripCancelled = "driver.cmd.trip_cancelled"
+	DriverCmdLocation      = "driver.cmd.location"
+	DriverCmdRegister      = "driver.cmd.register"
+	// DriverCmdStatus is sent by a driver going available, on a break or offline
+	DriverCmdStatus = "driver.cmd.status"
+
+	// Driver events (driver.event.*)
+	DriverEventSupply = "driver.event.supply"
+	// DriverEventOffline is published when a driver stopped sending heartbeats
+	DriverEventOffline = "driver.event.offline"
+	// DriverEventLocation is published for every move of a simulated driver
+	DriverEventLocation = "driver.event.location"
 
 	// Payment events (payment.event.*)
 	PaymentEventSessionCreated = "payment.event.session_created"
@@ -2,6 +2,9 @@ package contracts
 
 import "encoding/json"
 
+// WSMessageTypeError is the type of the frames sent back when a client message is rejected.
+const WSMessageTypeError = "error"
+
 // WSMessage is the message structure for the WebSocket.
 type WSMessage struct {
 	Type string `json:"type"`
@@ -0,0 +1,52 @@
+/*
+Package db provides helpers to connect to the databases used by the services.
+*/
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const (
+	TripsCollection     = "trips"
+	RideFaresCollection = "ride_fares"
+)
+
+type MongoConfig struct {
+	URI            string
+	Database       string
+	ConnectTimeout time.Duration
+}
+
+// NewMongoClient connects to MongoDB and makes sure the server answers.
+func NewMongoClient(ctx context.Context, cfg MongoConfig) (*mongo.Client, error) {
+	if cfg.URI == "" {
+		return nil, fmt.Errorf("mongodb URI is required")
+	}
+
+	timeout := cfg.ConnectTimeout
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI).SetConnectTimeout(timeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to mongodb: %w", err)
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := client.Ping(pingCtx, readpref.Primary()); err != nil {
+		_ = client.Disconnect(ctx)
+		return nil, fmt.Errorf("failed to ping mongodb: %w", err)
+	}
+
+	return client, nil
+}
@@ -0,0 +1,79 @@
+package messaging
+
+import (
+	"errors"
+	"log"
+	"sync"
+
+	"ride-sharing/shared/contracts"
+
+	"github.com/gorilla/websocket"
+)
+
+var ErrConnectionNotFound = errors.New("connection not found")
+
+// connWrapper serialises writes, gorilla/websocket allows only one concurrent writer per connection.
+type connWrapper struct {
+	conn *websocket.Conn
+	mu   sync.Mutex
+}
+
+// ConnectionManager keeps track of the open WebSocket connections keyed by user or driver ID.
+type ConnectionManager struct {
+	connections map[string]*connWrapper
+	mu          sync.RWMutex
+}
+
+func NewConnectionManager() *ConnectionManager {
+	return &ConnectionManager{
+		connections: make(map[string]*connWrapper),
+	}
+}
+
+// Add registers the connection for id, replacing (and closing) any previous one.
+func (cm *ConnectionManager) Add(id string, conn *websocket.Conn) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if existing, ok := cm.connections[id]; ok && existing.conn != conn {
+		log.Printf("Replacing existing connection for %s", id)
+		existing.conn.Close()
+	}
+
+	cm.connections[id] = &connWrapper{conn: conn}
+}
+
+// Remove drops the connection for id, as long as it is still the one given.
+// This keeps a reconnect from being unregistered by the old socket's cleanup.
+func (cm *ConnectionManager) Remove(id string, conn *websocket.Conn) {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	if existing, ok := cm.connections[id]; ok && existing.conn == conn {
+		delete(cm.connections, id)
+	}
+}
+
+func (cm *ConnectionManager) Has(id string) bool {
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	_, ok := cm.connections[id]
+	return ok
+}
+
+// SendMessage writes the message to the connection registered for id.
+func (cm *ConnectionManager) SendMessage(id string, message contracts.WSMessage) error {
+	cm.mu.RLock()
+	wrapper, ok := cm.connections[id]
+	cm.mu.RUnlock()
+
+	if !ok {
+		return ErrConnectionNotFound
+	}
+
+	wrapper.mu.Lock()
+	defer wrapper.mu.Unlock()
+
+	return wrapper.conn.WriteJSON(message)
+}
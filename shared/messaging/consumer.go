@@ -0,0 +1,250 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// consumerDrainTimeout is how long Close waits for the handlers still running.
+const consumerDrainTimeout = 10 * time.Second
+
+// ConsumerOptions tune how many messages a consumer handles at once.
+type ConsumerOptions struct {
+	// Prefetch is how many unacked messages the broker hands out, at least one per worker
+	Prefetch int
+	// Workers handle messages concurrently, keep 1 when the order of the messages matters
+	Workers int
+	// HandlerTimeout cancels the handler context of a delivery, 0 means no timeout.
+	// A timed out message is retried like any failure.
+	HandlerTimeout time.Duration
+}
+
+func DefaultConsumerOptions() ConsumerOptions {
+	return ConsumerOptions{
+		Prefetch:       1,
+		Workers:        1,
+		HandlerTimeout: 30 * time.Second,
+	}
+}
+
+func (o ConsumerOptions) normalized() ConsumerOptions {
+	o.Workers = max(o.Workers, 1)
+	o.Prefetch = max(o.Prefetch, o.Workers)
+
+	return o
+}
+
+type consumer struct {
+	// queue is empty for an exclusive queue, a new one is declared on every connection
+	queue       string
+	routingKeys []string
+	handler     MessageHandler
+	opts        ConsumerOptions
+	// policy applies to work queues, failed messages of exclusive queues are dropped
+	policy RetryPolicy
+
+	// ctx comes from the caller, cancelling it stops the consumer and its running handlers
+	ctx context.Context
+}
+
+// interface
+type MessageHandler func(context.Context, amqp.Delivery) error
+
+// ConsumeMessages handles the messages of a durable queue until ctx is cancelled, also after
+// reconnecting. A failing message is retried with a growing delay, then moved to the dead-letter queue.
+func (r *RabbitMQ) ConsumeMessages(ctx context.Context, queueName string, opts ConsumerOptions, handler MessageHandler) error {
+	return r.addConsumer(&consumer{
+		queue:   queueName,
+		handler: handler,
+		opts:    opts.normalized(),
+		policy:  DefaultRetryPolicy(),
+		ctx:     ctx,
+	})
+}
+
+// ConsumeExclusive handles the messages of a server-named queue that lives as long as the
+// connection, bound to the given routing keys. Every instance gets its own copy of the messages.
+func (r *RabbitMQ) ConsumeExclusive(ctx context.Context, routingKeys []string, opts ConsumerOptions, handler MessageHandler) error {
+	return r.addConsumer(&consumer{
+		routingKeys: routingKeys,
+		handler:     handler,
+		opts:        opts.normalized(),
+		ctx:         ctx,
+	})
+}
+
+// addConsumer subscribes right away when connected, otherwise on the next connection.
+func (r *RabbitMQ) addConsumer(c *consumer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrClosed
+	}
+
+	if r.channel != nil {
+		if err := r.subscribe(r.channel, c); err != nil {
+			return err
+		}
+	}
+
+	r.consumers = append(r.consumers, c)
+
+	return nil
+}
+
+// subscribe starts the workers of the consumer on the channel. Must be called with r.mu held.
+func (r *RabbitMQ) subscribe(ch *amqp.Channel, c *consumer) error {
+	if c.ctx.Err() != nil {
+		// stopped, nothing to resubscribe
+		return nil
+	}
+
+	queueName := c.queue
+	if queueName == "" {
+		name, err := declareExclusiveQueue(ch, c.routingKeys)
+		if err != nil {
+			return err
+		}
+
+		queueName = name
+	}
+
+	// applies to the consumers started on the channel from now on, so each one gets its own
+	if err := ch.Qos(
+		c.opts.Prefetch, // prefetchCount: unacked messages handed to this consumer at a time
+		0,               // prefetchSize: no limit in bytes
+		false,           // global: apply prefetch to each consumer individually
+	); err != nil {
+		return fmt.Errorf("failed to set Qos: %v", err)
+	}
+
+	tag := "ctag-" + uuid.NewString()
+
+	msgs, err := ch.Consume(
+		queueName, // queue
+		tag,       // consumer
+		false,     // auto-ack
+		false,     // exclusive
+		false,     // no-local
+		false,     // no-wait
+		nil,       // args
+	)
+
+	if err != nil {
+		return err
+	}
+
+	// msgs is closed with the channel, the consumer is subscribed again once reconnected
+	var workers sync.WaitGroup
+	for i := 0; i < c.opts.Workers; i++ {
+		workers.Add(1)
+		r.workers.Add(1)
+
+		go func() {
+			defer r.workers.Done()
+			defer workers.Done()
+
+			r.work(c, msgs)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	go func() {
+		select {
+		case <-c.ctx.Done():
+		case <-r.ctx.Done():
+		case <-done:
+			return
+		}
+
+		// the broker stops delivering, msgs is closed once the prefetched messages are handed back
+		if err := ch.Cancel(tag, false); err != nil {
+			log.Printf("Failed to cancel consumer of %s: %v", queueName, err)
+		}
+	}()
+
+	return nil
+}
+
+// work handles deliveries until msgs is closed. Once the consumer is stopping, the
+// deliveries not started yet are requeued for another instance.
+func (r *RabbitMQ) work(c *consumer, msgs <-chan amqp.Delivery) {
+	for msg := range msgs {
+		if c.ctx.Err() != nil || r.ctx.Err() != nil {
+			_ = msg.Nack(false, true)
+			continue
+		}
+
+		r.deliver(c, msg)
+	}
+}
+
+func (r *RabbitMQ) deliver(c *consumer, msg amqp.Delivery) {
+	msg.RoutingKey = originalRoutingKey(msg)
+
+	ctx := withCause(c.ctx, msg)
+	if c.opts.HandlerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opts.HandlerTimeout)
+		defer cancel()
+	}
+
+	err := handleSafely(ctx, c.handler, msg)
+	if err == nil {
+		// Only Ack if the handler succeeds
+		_ = msg.Ack(false)
+		return
+	}
+
+	if c.ctx.Err() != nil {
+		// cut short by the shutdown, not the message's fault
+		log.Printf("Requeueing %s message interrupted by shutdown: %v", msg.RoutingKey, err)
+		_ = msg.Nack(false, true)
+		return
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("handler timed out after %s: %w", c.opts.HandlerTimeout, err)
+	}
+
+	r.handleFailure(c, msg, err)
+}
+
+// handleSafely turns a panicking handler into a poison message rather than a crash loop.
+func handleSafely(ctx context.Context, handler MessageHandler, msg amqp.Delivery) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = Poison(fmt.Errorf("handler panicked: %v", rec))
+		}
+	}()
+
+	return handler(ctx, msg)
+}
+
+// drainConsumers waits for the running handlers to ack or requeue their message.
+func (r *RabbitMQ) drainConsumers() {
+	drained := make(chan struct{})
+	go func() {
+		r.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(consumerDrainTimeout):
+		log.Printf("Consumers still busy after %s, their messages go back to the queue", consumerDrainTimeout)
+	}
+}
@@ -0,0 +1,296 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// DeadLetterExchange receives the messages the consumers gave up on, routed by their queue name
+	DeadLetterExchange = "trip.dlx"
+
+	// the retry and dead-letter queues are reached through the default exchange, the
+	// routing key the message was published with is kept in a header
+	headerRoutingKey = "x-original-routing-key"
+	headerAttempts   = "x-attempts"
+	headerLastError  = "x-last-error"
+	headerDeadReason = "x-dead-reason"
+
+	deadReasonPoison      = "poison"
+	deadReasonMaxAttempts = "max_attempts"
+)
+
+var ErrPoisonMessage = errors.New("poison message")
+
+// Poison marks a handler error as permanent, typically a message that can't be decoded.
+// The message goes straight to the dead-letter queue instead of being retried.
+func Poison(err error) error {
+	return fmt.Errorf("%w: %w", ErrPoisonMessage, err)
+}
+
+// RetryPolicy is how many times a failing message is handled, and how long apart.
+// The delay doubles after every attempt.
+type RetryPolicy struct {
+	// MaxAttempts includes the first one, 1 dead-letters on the first failure
+	MaxAttempts  int
+	InitialDelay time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:  5,
+		InitialDelay: 1 * time.Second,
+	}
+}
+
+// delay is the wait after the given number of failed attempts.
+func (p RetryPolicy) delay(attempts int) time.Duration {
+	return p.InitialDelay << (attempts - 1)
+}
+
+// DeadLetterQueue is where the messages of a work queue end up once they can't be handled.
+func DeadLetterQueue(queue string) string {
+	return queue + ".dlq"
+}
+
+// retryQueue holds messages for the delay, it is part of the name so changing the policy
+// declares new queues instead of clashing with the old ones.
+func retryQueue(queue string, delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%dms", queue, delay.Milliseconds())
+}
+
+// declareRetryTopology declares the dead-letter queue of a work queue, and a delay queue per
+// retry that hands the message back to the work queue once its TTL is over.
+func declareRetryTopology(ch *amqp.Channel, queue string, policy RetryPolicy) error {
+	if err := ch.ExchangeDeclare(DeadLetterExchange, "direct", true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %w", DeadLetterExchange, err)
+	}
+
+	dlq, err := ch.QueueDeclare(DeadLetterQueue(queue), true, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", DeadLetterQueue(queue), err)
+	}
+
+	if err := ch.QueueBind(dlq.Name, queue, DeadLetterExchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind queue %s: %w", dlq.Name, err)
+	}
+
+	for attempts := 1; attempts < policy.MaxAttempts; attempts++ {
+		delay := policy.delay(attempts)
+
+		if _, err := ch.QueueDeclare(retryQueue(queue, delay), true, false, false, false, amqp.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queue,
+		}); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", retryQueue(queue, delay), err)
+		}
+	}
+
+	return nil
+}
+
+// handleFailure schedules a retry of the message or, once it is out of attempts or poisoned,
+// moves it to the dead-letter queue. Messages of exclusive queues are dropped.
+func (r *RabbitMQ) handleFailure(c *consumer, msg amqp.Delivery, handlerErr error) {
+	if c.queue == "" {
+		log.Printf("Dropping %s message: %v", msg.RoutingKey, handlerErr)
+		_ = msg.Nack(false, false)
+		return
+	}
+
+	attempts := deliveryAttempts(msg) + 1
+
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+
+	headers[headerRoutingKey] = msg.RoutingKey
+	headers[headerAttempts] = int32(attempts)
+	headers[headerLastError] = handlerErr.Error()
+
+	exchange, routingKey, deadReason := c.policy.failureRoute(c.queue, attempts, handlerErr)
+
+	switch deadReason {
+	case "":
+		log.Printf("Retrying %s message from %s in %s (attempt %d/%d): %v", msg.RoutingKey, c.queue, c.policy.delay(attempts), attempts, c.policy.MaxAttempts, handlerErr)
+	case deadReasonPoison:
+		log.Printf("Dead-lettering poison %s message from %s: %v", msg.RoutingKey, c.queue, handlerErr)
+	default:
+		log.Printf("Dead-lettering %s message from %s after %d attempts: %v", msg.RoutingKey, c.queue, attempts, handlerErr)
+	}
+
+	if deadReason != "" {
+		headers[headerDeadReason] = deadReason
+	}
+
+	if err := r.publish(context.Background(), exchange, routingKey, amqp.Publishing{
+		Headers:       headers,
+		ContentType:   msg.ContentType,
+		Body:          msg.Body,
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     msg.MessageId,
+		CorrelationId: msg.CorrelationId,
+		Type:          msg.Type,
+		Timestamp:     msg.Timestamp,
+	}); err != nil {
+		// better handled twice than lost
+		log.Printf("Failed to move the failed message, requeueing it: %v", err)
+		_ = msg.Nack(false, true)
+		return
+	}
+
+	_ = msg.Ack(false)
+}
+
+// failureRoute is where a message of the queue goes after failing the given number of attempts,
+// a delay queue or the dead-letter exchange. deadReason is empty for a retry.
+func (p RetryPolicy) failureRoute(queue string, attempts int, handlerErr error) (exchange, routingKey, deadReason string) {
+	switch {
+	case errors.Is(handlerErr, ErrPoisonMessage):
+		return DeadLetterExchange, queue, deadReasonPoison
+	case attempts >= p.MaxAttempts:
+		return DeadLetterExchange, queue, deadReasonMaxAttempts
+	}
+
+	return "", retryQueue(queue, p.delay(attempts)), ""
+}
+
+// deliveryAttempts is how many times the message failed before.
+func deliveryAttempts(msg amqp.Delivery) int {
+	switch n := msg.Headers[headerAttempts].(type) {
+	case int32:
+		return int(n)
+	case int64:
+		return int(n)
+	case int:
+		return n
+	}
+
+	return 0
+}
+
+// originalRoutingKey undoes the detour through the retry queues.
+func originalRoutingKey(msg amqp.Delivery) string {
+	if key, ok := msg.Headers[headerRoutingKey].(string); ok && key != "" {
+		return key
+	}
+
+	return msg.RoutingKey
+}
+
+// DeadLetter is a message sitting in a dead-letter queue.
+type DeadLetter struct {
+	MessageID  string
+	RoutingKey string
+	Attempts   int
+	Reason     string
+	LastError  string
+	Body       []byte
+}
+
+// InspectDeadLetters returns up to limit messages of the queue's dead-letter queue, leaving them there.
+func (r *RabbitMQ) InspectDeadLetters(queue string, limit int) ([]DeadLetter, error) {
+	ch, err := r.currentChannel()
+	if err != nil {
+		return nil, err
+	}
+
+	var held []amqp.Delivery
+
+	// unacked messages are not handed out again, so every Get returns the next one
+	defer func() {
+		for _, d := range held {
+			_ = d.Nack(false, true)
+		}
+	}()
+
+	letters := []DeadLetter{}
+
+	for len(letters) < limit {
+		d, ok, err := ch.Get(DeadLetterQueue(queue), false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", DeadLetterQueue(queue), err)
+		}
+
+		if !ok {
+			break
+		}
+
+		held = append(held, d)
+
+		reason, _ := d.Headers[headerDeadReason].(string)
+		lastError, _ := d.Headers[headerLastError].(string)
+
+		letters = append(letters, DeadLetter{
+			MessageID:  d.MessageId,
+			RoutingKey: originalRoutingKey(d),
+			Attempts:   deliveryAttempts(d),
+			Reason:     reason,
+			LastError:  lastError,
+			Body:       d.Body,
+		})
+	}
+
+	return letters, nil
+}
+
+// ReplayDeadLetters moves up to limit messages from the dead-letter queue back to the work queue,
+// with a fresh set of attempts. It returns how many were moved.
+func (r *RabbitMQ) ReplayDeadLetters(ctx context.Context, queue string, limit int) (int, error) {
+	ch, err := r.currentChannel()
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+
+	for replayed < limit {
+		d, ok, err := ch.Get(DeadLetterQueue(queue), false)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to read %s: %w", DeadLetterQueue(queue), err)
+		}
+
+		if !ok {
+			break
+		}
+
+		headers := amqp.Table{}
+		for k, v := range d.Headers {
+			headers[k] = v
+		}
+
+		headers[headerRoutingKey] = originalRoutingKey(d)
+		delete(headers, headerAttempts)
+		delete(headers, headerLastError)
+		delete(headers, headerDeadReason)
+
+		if err := r.publish(ctx, "", queue, amqp.Publishing{
+			Headers:       headers,
+			ContentType:   d.ContentType,
+			Body:          d.Body,
+			DeliveryMode:  amqp.Persistent,
+			MessageId:     d.MessageId,
+			CorrelationId: d.CorrelationId,
+			Type:          d.Type,
+			Timestamp:     d.Timestamp,
+		}); err != nil {
+			_ = d.Nack(false, true)
+			return replayed, fmt.Errorf("failed to replay message %s: %w", d.MessageId, err)
+		}
+
+		if err := d.Ack(false); err != nil {
+			return replayed, fmt.Errorf("failed to remove message %s from %s: %w", d.MessageId, DeadLetterQueue(queue), err)
+		}
+
+		replayed++
+	}
+
+	return replayed, nil
+}
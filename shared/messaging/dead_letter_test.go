@@ -0,0 +1,168 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialDelay: time.Second}
+
+	tests := []struct {
+		attempts  int
+		wantDelay time.Duration
+		wantQueue string
+	}{
+		{attempts: 1, wantDelay: time.Second, wantQueue: "q.retry.1000ms"},
+		{attempts: 2, wantDelay: 2 * time.Second, wantQueue: "q.retry.2000ms"},
+		{attempts: 3, wantDelay: 4 * time.Second, wantQueue: "q.retry.4000ms"},
+		{attempts: 4, wantDelay: 8 * time.Second, wantQueue: "q.retry.8000ms"},
+	}
+
+	for _, tt := range tests {
+		delay := policy.delay(tt.attempts)
+		if delay != tt.wantDelay {
+			t.Errorf("delay(%d) = %s, want %s", tt.attempts, delay, tt.wantDelay)
+		}
+
+		if got := retryQueue("q", delay); got != tt.wantQueue {
+			t.Errorf("retryQueue after %d attempts = %s, want %s", tt.attempts, got, tt.wantQueue)
+		}
+	}
+}
+
+func TestFailureRoute(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialDelay: 500 * time.Millisecond}
+	failed := errors.New("handler failed")
+
+	tests := []struct {
+		name           string
+		attempts       int
+		err            error
+		wantExchange   string
+		wantRoutingKey string
+		wantReason     string
+	}{
+		{
+			name:           "first failure is retried",
+			attempts:       1,
+			err:            failed,
+			wantRoutingKey: "q.retry.500ms",
+		},
+		{
+			name:           "retried with a longer delay",
+			attempts:       2,
+			err:            failed,
+			wantRoutingKey: "q.retry.1000ms",
+		},
+		{
+			name:           "out of attempts",
+			attempts:       3,
+			err:            failed,
+			wantExchange:   DeadLetterExchange,
+			wantRoutingKey: "q",
+			wantReason:     deadReasonMaxAttempts,
+		},
+		{
+			name:           "poison is never retried",
+			attempts:       1,
+			err:            Poison(failed),
+			wantExchange:   DeadLetterExchange,
+			wantRoutingKey: "q",
+			wantReason:     deadReasonPoison,
+		},
+		{
+			name:           "wrapped poison",
+			attempts:       1,
+			err:            fmt.Errorf("trip t1: %w", Poison(failed)),
+			wantExchange:   DeadLetterExchange,
+			wantRoutingKey: "q",
+			wantReason:     deadReasonPoison,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exchange, routingKey, reason := policy.failureRoute("q", tt.attempts, tt.err)
+
+			if exchange != tt.wantExchange || routingKey != tt.wantRoutingKey || reason != tt.wantReason {
+				t.Fatalf("failureRoute = (%q, %q, %q), want (%q, %q, %q)",
+					exchange, routingKey, reason, tt.wantExchange, tt.wantRoutingKey, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestFailureRouteOnlyUsesDeclaredRetryQueues(t *testing.T) {
+	policy := DefaultRetryPolicy()
+
+	// declareRetryTopology declares a delay queue for every attempt but the last
+	declared := make(map[string]bool)
+	for attempts := 1; attempts < policy.MaxAttempts; attempts++ {
+		declared[retryQueue("q", policy.delay(attempts))] = true
+	}
+
+	for attempts := 1; attempts < policy.MaxAttempts; attempts++ {
+		exchange, routingKey, _ := policy.failureRoute("q", attempts, errors.New("failed"))
+		if exchange == "" && !declared[routingKey] {
+			t.Fatalf("attempt %d is retried through %s, which is not declared", attempts, routingKey)
+		}
+	}
+}
+
+func TestDeliveryHeaders(t *testing.T) {
+	tests := []struct {
+		name           string
+		msg            amqp.Delivery
+		wantAttempts   int
+		wantRoutingKey string
+	}{
+		{
+			name:           "first delivery",
+			msg:            amqp.Delivery{RoutingKey: "trip.event.created"},
+			wantRoutingKey: "trip.event.created",
+		},
+		{
+			name: "back from a retry queue",
+			msg: amqp.Delivery{
+				RoutingKey: "find_available_drivers",
+				Headers:    amqp.Table{headerAttempts: int32(2), headerRoutingKey: "trip.event.created"},
+			},
+			wantAttempts:   2,
+			wantRoutingKey: "trip.event.created",
+		},
+		{
+			name: "attempts decoded as int64",
+			msg: amqp.Delivery{
+				RoutingKey: "trip.event.created",
+				Headers:    amqp.Table{headerAttempts: int64(3)},
+			},
+			wantAttempts:   3,
+			wantRoutingKey: "trip.event.created",
+		},
+		{
+			name: "unexpected header types",
+			msg: amqp.Delivery{
+				RoutingKey: "trip.event.created",
+				Headers:    amqp.Table{headerAttempts: "2", headerRoutingKey: ""},
+			},
+			wantRoutingKey: "trip.event.created",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deliveryAttempts(tt.msg); got != tt.wantAttempts {
+				t.Fatalf("deliveryAttempts = %d, want %d", got, tt.wantAttempts)
+			}
+
+			if got := originalRoutingKey(tt.msg); got != tt.wantRoutingKey {
+				t.Fatalf("originalRoutingKey = %s, want %s", got, tt.wantRoutingKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"ride-sharing/shared/contracts"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	headerSchemaVersion = "x-schema-version"
+	headerCausationID   = "x-causation-id"
+
+	// legacySchemaVersion is what the payloads were before they got a version, they
+	// were published as {ownerId, data} with data base64 encoded
+	legacySchemaVersion = 1
+)
+
+// ErrSchemaMismatch means the event was published with a payload version this service can't read.
+var ErrSchemaMismatch = errors.New("incompatible schema version")
+
+// Payload is the data of an event. SchemaVersion is bumped on breaking changes only, adding
+// a field keeps it, so consumers can refuse the versions they don't know instead of misreading them.
+type Payload interface {
+	SchemaVersion() int
+}
+
+// Event is a decoded envelope with its typed payload.
+type Event[T Payload] struct {
+	contracts.EventEnvelope
+	Payload T
+}
+
+// PublishEvent wraps the payload in an envelope and publishes it. When ctx comes from a
+// consumer handler, the event is correlated with the one being handled.
+func PublishEvent[T Payload](ctx context.Context, r *RabbitMQ, routingKey, ownerID string, payload T) error {
+	env, err := newEnvelope(ctx, routingKey, ownerID, payload)
+	if err != nil {
+		return err
+	}
+
+	return r.publishEnvelope(ctx, env)
+}
+
+func newEnvelope(ctx context.Context, routingKey, ownerID string, payload Payload) (contracts.EventEnvelope, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return contracts.EventEnvelope{}, fmt.Errorf("failed to marshal %s payload: %w", routingKey, err)
+	}
+
+	env := contracts.EventEnvelope{
+		EventID:       uuid.NewString(),
+		Type:          routingKey,
+		SchemaVersion: payload.SchemaVersion(),
+		OccurredAt:    time.Now().UTC(),
+		OwnerID:       ownerID,
+		Data:          data,
+	}
+
+	if c, ok := ctx.Value(causeKey{}).(cause); ok {
+		env.CorrelationID = c.correlationID
+		env.CausationID = c.eventID
+	} else {
+		// first of its chain
+		env.CorrelationID = env.EventID
+	}
+
+	return env, nil
+}
+
+// DecodeEvent decodes the envelope of the message and its payload.
+func DecodeEvent[T Payload](msg amqp.Delivery) (Event[T], error) {
+	env, err := DecodeEnvelope(msg)
+	if err != nil {
+		return Event[T]{}, err
+	}
+
+	payload, err := DecodePayload[T](env)
+	if err != nil {
+		return Event[T]{}, err
+	}
+
+	return Event[T]{EventEnvelope: env, Payload: payload}, nil
+}
+
+// DecodeEnvelope decodes the envelope of the message, leaving the payload as is.
+// Messages published before the envelope existed are converted.
+func DecodeEnvelope(msg amqp.Delivery) (contracts.EventEnvelope, error) {
+	var env contracts.EventEnvelope
+	if err := json.Unmarshal(msg.Body, &env); err != nil {
+		return env, fmt.Errorf("failed to unmarshal %s envelope: %w", msg.RoutingKey, err)
+	}
+
+	if env.EventID == "" && env.SchemaVersion == 0 {
+		return legacyEnvelope(msg, env)
+	}
+
+	return env, nil
+}
+
+// DecodePayload decodes the payload of the envelope, if its version is the one T reads.
+func DecodePayload[T Payload](env contracts.EventEnvelope) (T, error) {
+	var payload T
+
+	if want := payload.SchemaVersion(); env.SchemaVersion != want {
+		return payload, fmt.Errorf("%w: %s payload is v%d, expected v%d", ErrSchemaMismatch, env.Type, env.SchemaVersion, want)
+	}
+
+	if err := json.Unmarshal(env.Data, &payload); err != nil {
+		return payload, fmt.Errorf("failed to unmarshal %s payload: %w", env.Type, err)
+	}
+
+	return payload, nil
+}
+
+// legacyEnvelope fills in what the old {ownerId, data} messages didn't carry from the delivery.
+func legacyEnvelope(msg amqp.Delivery, env contracts.EventEnvelope) (contracts.EventEnvelope, error) {
+	var data []byte
+	if len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, &data); err != nil {
+			return env, fmt.Errorf("failed to unmarshal legacy %s data: %w", msg.RoutingKey, err)
+		}
+	}
+
+	env.EventID = msg.MessageId
+	env.Type = msg.RoutingKey
+	env.SchemaVersion = legacySchemaVersion
+	env.OccurredAt = msg.Timestamp
+	env.CorrelationID = msg.MessageId
+	env.Data = data
+
+	return env, nil
+}
+
+// publishEnvelope publishes the event with its routing key. The metadata is repeated in the
+// message properties, so it is visible in the management UI and to handlers without decoding.
+func (r *RabbitMQ) publishEnvelope(ctx context.Context, env contracts.EventEnvelope) error {
+	body, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %v", err)
+	}
+
+	headers := amqp.Table{
+		headerSchemaVersion: int32(env.SchemaVersion),
+	}
+
+	if env.CausationID != "" {
+		headers[headerCausationID] = env.CausationID
+	}
+
+	return r.publish(ctx, TripExchange, env.Type, amqp.Publishing{
+		Headers:       headers,
+		ContentType:   "application/json",
+		Body:          body,
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     env.EventID,
+		CorrelationId: env.CorrelationID,
+		Type:          env.Type,
+		Timestamp:     env.OccurredAt,
+	})
+}
+
+type causeKey struct{}
+
+type cause struct {
+	eventID       string
+	correlationID string
+}
+
+// withCause makes the events published while handling msg follow from it.
+func withCause(ctx context.Context, msg amqp.Delivery) context.Context {
+	if msg.MessageId == "" {
+		return ctx
+	}
+
+	correlationID := msg.CorrelationId
+	if correlationID == "" {
+		correlationID = msg.MessageId
+	}
+
+	return context.WithValue(ctx, causeKey{}, cause{
+		eventID:       msg.MessageId,
+		correlationID: correlationID,
+	})
+}
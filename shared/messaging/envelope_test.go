@@ -0,0 +1,189 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"ride-sharing/shared/contracts"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// offlineV2 stands for a future, incompatible version of DriverOfflineData.
+type offlineV2 struct {
+	DriverID string `json:"driverID"`
+}
+
+func (offlineV2) SchemaVersion() int { return 2 }
+
+func delivery(t *testing.T, env contracts.EventEnvelope) amqp.Delivery {
+	t.Helper()
+
+	body, err := json.Marshal(env)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	return amqp.Delivery{RoutingKey: env.Type, MessageId: env.EventID, Body: body}
+}
+
+func TestDecodeEvent(t *testing.T) {
+	payload := DriverOfflineData{DriverID: "d1", TripID: "t1", Status: "on_trip", LastSeenAt: time.Unix(1700000000, 0).UTC()}
+
+	current, err := newEnvelope(context.Background(), contracts.DriverEventOffline, "d1", payload)
+	if err != nil {
+		t.Fatalf("newEnvelope: %v", err)
+	}
+
+	future, err := newEnvelope(context.Background(), contracts.DriverEventOffline, "d1", offlineV2{DriverID: "d1"})
+	if err != nil {
+		t.Fatalf("newEnvelope: %v", err)
+	}
+
+	legacyData, _ := json.Marshal(payload)
+	legacyBody, _ := json.Marshal(map[string]any{"ownerId": "d1", "data": legacyData})
+	sentAt := time.Unix(1600000000, 0).UTC()
+
+	tests := []struct {
+		name         string
+		msg          amqp.Delivery
+		wantErr      error
+		wantAnyErr   bool
+		wantVersion  int
+		wantOwnerID  string
+		wantEventID  string
+		wantOccurred time.Time
+	}{
+		{
+			name:        "current envelope",
+			msg:         delivery(t, current),
+			wantVersion: 1,
+			wantOwnerID: "d1",
+			wantEventID: current.EventID,
+		},
+		{
+			name: "legacy message",
+			msg: amqp.Delivery{
+				RoutingKey: contracts.DriverEventOffline,
+				MessageId:  "legacy-1",
+				Timestamp:  sentAt,
+				Body:       legacyBody,
+			},
+			wantVersion:  legacySchemaVersion,
+			wantOwnerID:  "d1",
+			wantEventID:  "legacy-1",
+			wantOccurred: sentAt,
+		},
+		{
+			name:    "newer version than we read",
+			msg:     delivery(t, future),
+			wantErr: ErrSchemaMismatch,
+		},
+		{
+			name:       "not json",
+			msg:        amqp.Delivery{RoutingKey: contracts.DriverEventOffline, Body: []byte("offline")},
+			wantAnyErr: true,
+		},
+		{
+			name: "payload of the wrong shape",
+			msg: delivery(t, contracts.EventEnvelope{
+				EventID:       "e1",
+				Type:          contracts.DriverEventOffline,
+				SchemaVersion: 1,
+				Data:          json.RawMessage(`["d1"]`),
+			}),
+			wantAnyErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := DecodeEvent[DriverOfflineData](tt.msg)
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DecodeEvent error = %v, want %v", err, tt.wantErr)
+				}
+				return
+
+			case tt.wantAnyErr:
+				if err == nil || errors.Is(err, ErrSchemaMismatch) {
+					t.Fatalf("DecodeEvent error = %v, want a decoding error", err)
+				}
+				return
+
+			case err != nil:
+				t.Fatalf("DecodeEvent: %v", err)
+			}
+
+			if event.SchemaVersion != tt.wantVersion || event.OwnerID != tt.wantOwnerID || event.EventID != tt.wantEventID {
+				t.Fatalf("envelope = %+v", event.EventEnvelope)
+			}
+
+			if event.Type != contracts.DriverEventOffline {
+				t.Fatalf("Type = %s, want %s", event.Type, contracts.DriverEventOffline)
+			}
+
+			if !tt.wantOccurred.IsZero() && !event.OccurredAt.Equal(tt.wantOccurred) {
+				t.Fatalf("OccurredAt = %v, want %v", event.OccurredAt, tt.wantOccurred)
+			}
+
+			if event.Payload != payload {
+				t.Fatalf("Payload = %+v, want %+v", event.Payload, payload)
+			}
+		})
+	}
+}
+
+func TestNewEnvelopeCausation(t *testing.T) {
+	tests := []struct {
+		name            string
+		handling        *amqp.Delivery
+		wantCorrelation string
+		wantCausation   string
+	}{
+		{
+			name: "first of its chain",
+		},
+		{
+			name:            "follows the handled event",
+			handling:        &amqp.Delivery{MessageId: "e1", CorrelationId: "c1"},
+			wantCorrelation: "c1",
+			wantCausation:   "e1",
+		},
+		{
+			name:            "handled event without a correlation",
+			handling:        &amqp.Delivery{MessageId: "e1"},
+			wantCorrelation: "e1",
+			wantCausation:   "e1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.handling != nil {
+				ctx = withCause(ctx, *tt.handling)
+			}
+
+			env, err := newEnvelope(ctx, contracts.TripEventCreated, "u1", TripEventData{})
+			if err != nil {
+				t.Fatalf("newEnvelope: %v", err)
+			}
+
+			wantCorrelation := tt.wantCorrelation
+			if wantCorrelation == "" {
+				wantCorrelation = env.EventID
+			}
+
+			if env.EventID == "" || env.CorrelationID != wantCorrelation || env.CausationID != tt.wantCausation {
+				t.Fatalf("envelope ids = (%q, %q, %q), want correlation %q and causation %q",
+					env.EventID, env.CorrelationID, env.CausationID, wantCorrelation, tt.wantCausation)
+			}
+		})
+	}
+}
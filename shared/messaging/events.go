@@ -1,13 +1,64 @@
 package messaging
 
 import (
+	"time"
+
+	pbd "ride-sharing/shared/proto/driver"
 	pb "ride-sharing/shared/proto/trip"
 )
 
 const (
 	FindAvailableDriversQueue = "find_available_drivers"
+	DriverTripResponseQueue   = "driver_trip_response"
 )
 
 type TripEventData struct {
 	Trip *pb.Trip `json:"trip"`
 }
+
+// DriverTripResponseData is sent by a driver accepting or declining a trip request.
+type DriverTripResponseData struct {
+	Driver  *pbd.Driver `json:"driver"`
+	TripID  string      `json:"tripID"`
+	RiderID string      `json:"riderID"`
+}
+
+// CellSupply is the number of drivers of a package in a geohash cell.
+type CellSupply struct {
+	Geohash     string `json:"geohash"`
+	PackageSlug string `json:"packageSlug"`
+	Drivers     int    `json:"drivers"`
+}
+
+// DriverOfflineData is published for a driver who was marked offline for missing heartbeats,
+// TripID and Status are what they were doing at the time.
+type DriverOfflineData struct {
+	DriverID   string    `json:"driverID"`
+	TripID     string    `json:"tripID,omitempty"`
+	Status     string    `json:"status"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+// DriverLocationData is where a driver just moved to.
+type DriverLocationData struct {
+	DriverID string        `json:"driverID"`
+	Location *pbd.Location `json:"location"`
+	Geohash  string        `json:"geohash"`
+	Status   string        `json:"status"`
+	At       time.Time     `json:"at"`
+}
+
+// DriverSupplyData is a full snapshot of the available drivers, cells missing from it have none.
+type DriverSupplyData struct {
+	Precision uint         `json:"precision"`
+	Cells     []CellSupply `json:"cells"`
+	At        time.Time    `json:"at"`
+}
+
+// Schema versions of the payloads, see Payload.
+
+func (TripEventData) SchemaVersion() int          { return 1 }
+func (DriverTripResponseData) SchemaVersion() int { return 1 }
+func (DriverOfflineData) SchemaVersion() int      { return 1 }
+func (DriverSupplyData) SchemaVersion() int       { return 1 }
+func (DriverLocationData) SchemaVersion() int     { return 1 }
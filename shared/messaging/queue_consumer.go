@@ -0,0 +1,87 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"ride-sharing/shared/contracts"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// QueueConsumer forwards every message of the routing keys to the WebSocket owned by the message's OwnerID.
+// Each instance gets its own queue so a message reaches whichever instance holds the owner's socket.
+type QueueConsumer struct {
+	rabbitmq    *RabbitMQ
+	connManager *ConnectionManager
+	routingKeys []string
+}
+
+func NewQueueConsumer(rabbitmq *RabbitMQ, connManager *ConnectionManager, routingKeys []string) *QueueConsumer {
+	return &QueueConsumer{
+		rabbitmq:    rabbitmq,
+		connManager: connManager,
+		routingKeys: routingKeys,
+	}
+}
+
+// Start forwards the messages until ctx is cancelled. A single worker keeps them in order.
+func (qc *QueueConsumer) Start(ctx context.Context) error {
+	return qc.rabbitmq.ConsumeExclusive(ctx, qc.routingKeys, DefaultConsumerOptions(), func(ctx context.Context, msg amqp.Delivery) error {
+		message, err := DecodeEnvelope(msg)
+		if err != nil {
+			log.Printf("Failed to unmarshal message: %v", err)
+			return nil
+		}
+
+		if message.OwnerID == "" {
+			log.Printf("Dropping %s message without owner", msg.RoutingKey)
+			return nil
+		}
+
+		data, err := wsPayload(msg.RoutingKey, message)
+		if err != nil {
+			log.Printf("Failed to decode %s payload: %v", msg.RoutingKey, err)
+			return nil
+		}
+
+		err = qc.connManager.SendMessage(message.OwnerID, contracts.WSMessage{
+			Type: msg.RoutingKey,
+			Data: data,
+		})
+
+		if errors.Is(err, ErrConnectionNotFound) {
+			// the owner is connected to another gateway instance (or not at all)
+			return nil
+		}
+
+		if err != nil {
+			log.Printf("Failed to send %s to %s: %v", msg.RoutingKey, message.OwnerID, err)
+		}
+
+		return nil
+	})
+}
+
+// wsPayload turns the AMQP payload into what the web client expects for the given message type.
+// Trip events and trip requests carry a TripEventData, but the client wants the trip itself.
+func wsPayload(routingKey string, message contracts.EventEnvelope) (any, error) {
+	if len(message.Data) == 0 {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(routingKey, "trip.event.") || routingKey == contracts.DriverCmdTripRequest || routingKey == contracts.DriverCmdTripCancelled {
+		payload, err := DecodePayload[TripEventData](message)
+		if err != nil {
+			return nil, err
+		}
+
+		if payload.Trip != nil {
+			return payload.Trip, nil
+		}
+	}
+
+	return message.Data, nil
+}
@@ -2,11 +2,15 @@ package messaging
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"ride-sharing/shared/contracts"
+	"ride-sharing/shared/retry"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
@@ -14,119 +18,269 @@ const (
 	TripExchange = "trip"
 )
 
+var (
+	ErrNotConnected = errors.New("not connected to rabbitmq")
+	ErrClosed       = errors.New("rabbitmq connection is closed")
+	// ErrUnroutable means no queue is bound to the routing key, nobody got the message
+	ErrUnroutable    = errors.New("message is unroutable")
+	ErrPublishNacked = errors.New("message was rejected by the broker")
+)
+
+// publishConfirmTimeout is how long a publish waits for the broker to confirm the message.
+const publishConfirmTimeout = 5 * time.Second
+
+// ConnectionState is reported to the OnStateChange callback.
+type ConnectionState string
+
+const (
+	StateConnected    ConnectionState = "connected"
+	StateDisconnected ConnectionState = "disconnected"
+	StateClosed       ConnectionState = "closed"
+)
+
+var (
+	// connectRetry is used for the first connection, the service doesn't start without it
+	connectRetry = retry.Config{
+		MaxRetries:  5,
+		InitialWait: 1 * time.Second,
+		MaxWait:     10 * time.Second,
+	}
+
+	// reconnectRetry is used once the connection was lost, we start over until Close
+	reconnectRetry = retry.Config{
+		MaxRetries:  10,
+		InitialWait: 1 * time.Second,
+		MaxWait:     30 * time.Second,
+	}
+)
+
+// RabbitMQ keeps a connection to the broker, reconnecting with backoff when it is lost.
+// The topology is declared again and the consumers resubscribed on every connection.
 type RabbitMQ struct {
+	uri     string
 	conn    *amqp.Connection
-	Channel *amqp.Channel
+	channel *amqp.Channel
+	returns *returnTracker
+
+	consumers []*consumer
+	// workers counts the running consumer workers, Close waits for them
+	workers       sync.WaitGroup
+	onStateChange func(ConnectionState)
+	closed        bool
+
+	// ctx is cancelled by Close, stopping the reconnection and the consumers
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.RWMutex
 }
 
 func NewRabbitMQ(uri string) (*RabbitMQ, error) {
-	conn, err := amqp.Dial(uri)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	rmq := &RabbitMQ{
+		uri:    uri,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	if err := retry.WithBackoff(ctx, connectRetry, rmq.connect); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+
+	return rmq, nil
+}
+
+// OnStateChange registers fn to be called whenever the connection is lost, restored or closed,
+// so the service can report itself unhealthy in the meantime.
+func (r *RabbitMQ) OnStateChange(fn func(ConnectionState)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.onStateChange = fn
+}
+
+// connect dials the broker, declares the topology and resubscribes the consumers.
+func (r *RabbitMQ) connect() error {
+	conn, err := amqp.Dial(r.uri)
 	if err != nil {
-		log.Fatalf("Failed to connect to rabbitmq")
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %v", err)
+		return err
 	}
 
 	ch, err := conn.Channel()
-
 	if err != nil {
-		return nil, fmt.Errorf("failed to create channel: %v: ", err)
+		conn.Close()
+		return fmt.Errorf("failed to create channel: %w", err)
 	}
 
-	rmq := &RabbitMQ{
-		conn:    conn,
-		Channel: ch,
+	if err := setupExchangesAndQueues(ch); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to setup exchanges: %w", err)
 	}
 
-	if err = rmq.setupExchangesAndQueues(); err != nil {
-		rmq.Close()
-		return nil, fmt.Errorf("failed to setup exchanges: %v", err)
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
 	}
 
-	return rmq, nil
+	returns := newReturnTracker(ch)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		conn.Close()
+		return nil
+	}
+
+	for _, c := range r.consumers {
+		if err := r.subscribe(ch, c); err != nil {
+			conn.Close()
+			return err
+		}
+	}
+
+	r.conn = conn
+	r.channel = ch
+	r.returns = returns
+
+	// registered before anyone else can close them, so no closure is missed
+	connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	chanClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+
+	go r.watch(conn, connClosed, chanClosed)
+
+	return nil
 }
 
-func (r *RabbitMQ) PublishMessage(ctx context.Context, routingKey string, message contracts.AmqpMessage) error {
+// watch waits for the connection or the channel to go away and reconnects, unless we closed it.
+func (r *RabbitMQ) watch(conn *amqp.Connection, connClosed, chanClosed <-chan *amqp.Error) {
+	var reason *amqp.Error
+	select {
+	case reason = <-connClosed:
+	case reason = <-chanClosed:
+	}
 
-	jsonMsg, err := json.Marshal(message)
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
 
-	if err != nil {
-		return fmt.Errorf("failed to marshal message: %v", err)
-	}
-
-	log.Printf("Publishing message with routing key: %s", routingKey)
-	return r.Channel.PublishWithContext(ctx,
-		TripExchange, // exchange
-		routingKey,   // routing key
-		false,        // mandatory
-		false,        // immediate
-		amqp.Publishing{
-			ContentType:  "text/plain",
-			Body:         jsonMsg,
-			DeliveryMode: amqp.Persistent,
-		},
-	)
+	r.conn = nil
+	r.channel = nil
+	r.returns = nil
+	r.mu.Unlock()
+
+	// a channel error leaves the connection open, start over from scratch either way
+	conn.Close()
+
+	log.Printf("Lost the RabbitMQ connection: %v", reason)
+	r.setState(StateDisconnected)
+
+	for {
+		err := retry.WithBackoff(r.ctx, reconnectRetry, r.connect)
+		if err == nil {
+			break
+		}
+
+		if r.ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("Still unable to reach RabbitMQ: %v", err)
+	}
+
+	if r.isClosed() {
+		return
+	}
 
+	log.Println("Reconnected to RabbitMQ")
+	r.setState(StateConnected)
 }
 
-// interface
-type MessageHandler func(context.Context, amqp.Delivery) error
+func (r *RabbitMQ) setState(state ConnectionState) {
+	r.mu.RLock()
+	fn := r.onStateChange
+	r.mu.RUnlock()
 
-func (r *RabbitMQ) ConsumeMessages(queueName string, handler MessageHandler) error {
-	// Set prefetch count to 1 for fair dispatch
-	// This tells RabbitMQ not to give more than one message to a service at a time
-	// The worker will only get the next message after it has acknowledge the previous one
+	if fn != nil {
+		fn(state)
+	}
+}
 
-	err := r.Channel.Qos(
-		1,     // prefetchCount: Limit to 1 unacknowledge to service at a time.
-		0,     // This tells RabbitMQ not to give more than one message to service at a time.
-		false, // global: Apply prefetch to each consumer individually
-	)
+func (r *RabbitMQ) isClosed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 
-	if err != nil {
-		return fmt.Errorf("failed to set Qos: %v", err)
+	return r.closed
+}
+
+// publish publishes the message and waits for the broker to confirm it. The message is
+// mandatory, ErrUnroutable is returned when no queue is bound to the routing key.
+func (r *RabbitMQ) publish(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	r.mu.RLock()
+	ch, returns := r.channel, r.returns
+	r.mu.RUnlock()
+
+	if ch == nil {
+		return fmt.Errorf("failed to publish %s: %w", routingKey, ErrNotConnected)
 	}
 
-	msgs, err := r.Channel.Consume(
-		queueName, // queue
-		"",        // consumer
-		false,     // auto-ack
-		false,     // exclusive
-		false,     // no-local
-		false,     // no-wait
-		nil,       // args
+	if msg.MessageId == "" {
+		msg.MessageId = uuid.NewString()
+	}
+
+	returns.expect(msg.MessageId)
+
+	confirmation, err := ch.PublishWithDeferredConfirmWithContext(ctx,
+		exchange,
+		routingKey,
+		true,  // mandatory: get it back when no queue is bound
+		false, // immediate
+		msg,
 	)
 
 	if err != nil {
-		return err
+		returns.returned(msg.MessageId)
+		return fmt.Errorf("failed to publish %s: %w", routingKey, err)
 	}
 
-	ctx := context.Background()
-
-	go func() {
-		for msg := range msgs {
-			// log.Printf("Received a message: %s", msg.Body)
+	waitCtx, cancel := context.WithTimeout(ctx, publishConfirmTimeout)
+	defer cancel()
 
-			if err := handler(ctx, msg); err != nil {
-				log.Fatalf("failed to handle the message : %v", err)
+	acked, err := confirmation.WaitContext(waitCtx)
+	ret := returns.returned(msg.MessageId)
 
-				if nackErr := msg.Nack(false, false); nackErr != nil {
-					log.Printf("ERROR: failed to Nack message: %v", nackErr)
-				}
+	if err != nil {
+		return fmt.Errorf("no confirm for %s: %w", routingKey, err)
+	}
 
-				continue
-			}
+	if !acked {
+		return fmt.Errorf("failed to publish %s: %w", routingKey, ErrPublishNacked)
+	}
 
-			// Only Ack if the handler succeeds
-			_ = msg.Ack(false)
-		}
-	}()
+	if ret != nil {
+		return fmt.Errorf("failed to publish %s: %w: %s", routingKey, ErrUnroutable, ret.ReplyText)
+	}
 
 	return nil
 }
 
-func (r *RabbitMQ) setupExchangesAndQueues() error {
+func (r *RabbitMQ) currentChannel() (*amqp.Channel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.channel == nil {
+		return nil, ErrNotConnected
+	}
+
+	return r.channel, nil
+}
 
-	err := r.Channel.ExchangeDeclare(
+func setupExchangesAndQueues(ch *amqp.Channel) error {
+
+	err := ch.ExchangeDeclare(
 		TripExchange, // name
 		"topic",      //type
 		true,         // durable
@@ -137,10 +291,33 @@ func (r *RabbitMQ) setupExchangesAndQueues() error {
 
 	)
 
-	if err = r.declareAndBindQueue(
+	if err != nil {
+		return fmt.Errorf("failed to declare exchange %s: %w", TripExchange, err)
+	}
+
+	if err = declareAndBindQueue(
+		ch,
 		FindAvailableDriversQueue,
 		[]string{
 			contracts.TripEventCreated, contracts.TripEventDriverNotInterested,
+			// followed by the dispatcher to know who declined and when to stop
+			contracts.TripEventDriverAssigned, contracts.DriverCmdTripDecline,
+			// drivers are on the trip once it started and free again once it is over
+			contracts.TripEventStarted, contracts.TripEventCancelled, contracts.TripEventCompleted,
+		},
+		TripExchange,
+	); err != nil {
+		return err
+	}
+
+	if err = declareAndBindQueue(
+		ch,
+		DriverTripResponseQueue,
+		[]string{
+			// the dispatcher decisions too, so the trip is driver_offered until the driver
+			// answers and no_driver once nobody is left
+			contracts.DriverCmdTripRequest, contracts.TripEventNoDriversFound,
+			contracts.DriverCmdTripAccept, contracts.DriverCmdTripDecline,
 		},
 		TripExchange,
 	); err != nil {
@@ -150,8 +327,40 @@ func (r *RabbitMQ) setupExchangesAndQueues() error {
 	return nil
 }
 
-func (r *RabbitMQ) declareAndBindQueue(queueName string, messageTypes []string, exchange string) error {
-	q, err := r.Channel.QueueDeclare(
+// declareExclusiveQueue declares a server-named queue that lives as long as the connection,
+// bound to the given routing keys.
+func declareExclusiveQueue(ch *amqp.Channel, routingKeys []string) (string, error) {
+	q, err := ch.QueueDeclare(
+		"",    // name: let the server generate one
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to declare exclusive queue: %v", err)
+	}
+
+	for _, key := range routingKeys {
+		if err := ch.QueueBind(q.Name, key, TripExchange, false, nil); err != nil {
+			return "", fmt.Errorf("failed to bind queue %s to %s: %v", q.Name, key, err)
+		}
+	}
+
+	return q.Name, nil
+}
+
+// declareAndBindQueue declares a durable work queue along with its retry and dead-letter queues.
+// The arguments of an existing queue can't change, so what the broker itself rejects reaches the
+// dead-letter queue through a policy (see infra/development/k8s/rabbitmq-deployment.yaml), not x-dead-letter-* args.
+func declareAndBindQueue(ch *amqp.Channel, queueName string, messageTypes []string, exchange string) error {
+	if err := declareRetryTopology(ch, queueName, DefaultRetryPolicy()); err != nil {
+		return err
+	}
+
+	q, err := ch.QueueDeclare(
 		queueName, // name
 		true,      // durable messages will persists
 		false,     // delete when unused
@@ -161,11 +370,11 @@ func (r *RabbitMQ) declareAndBindQueue(queueName string, messageTypes []string,
 	)
 
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("failed to declare queue %s: %w", queueName, err)
 	}
 
 	for _, msg := range messageTypes {
-		if err := r.Channel.QueueBind(
+		if err := ch.QueueBind(
 			q.Name,   // queue name
 			msg,      // routing key
 			exchange, // exchange
@@ -180,12 +389,30 @@ func (r *RabbitMQ) declareAndBindQueue(queueName string, messageTypes []string,
 
 }
 
+// Close stops the consumers, waits for their running handlers, then closes the connection.
 func (r *RabbitMQ) Close() {
-	if r.conn != nil {
-		r.conn.Close()
+	r.mu.Lock()
+	r.closed = true
+	r.mu.Unlock()
+
+	// stops reconnecting and the consumers, the channel stays open for their acks
+	r.cancel()
+	r.drainConsumers()
+
+	r.mu.Lock()
+	conn, ch := r.conn, r.channel
+	r.conn = nil
+	r.channel = nil
+	r.returns = nil
+	r.mu.Unlock()
+
+	if ch != nil {
+		ch.Close()
 	}
 
-	if r.Channel != nil {
-		r.Channel.Close()
+	if conn != nil {
+		conn.Close()
 	}
+
+	r.setState(StateClosed)
 }
@@ -0,0 +1,89 @@
+package messaging
+
+import (
+	"log"
+	"sync"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// returnTracker matches the messages the broker sent back as unroutable to their publish.
+// The broker returns a message before acking it, and the channel hands the return to us
+// before processing the ack, so once a publish is confirmed, syncing with the reader is
+// enough to know whether it came back.
+type returnTracker struct {
+	returns  <-chan amqp.Return
+	barriers chan chan struct{}
+	// done is closed once the channel is gone and no more returns can come
+	done chan struct{}
+
+	// pending holds the message IDs being published, with their return once it came back
+	pending map[string]*amqp.Return
+	mu      sync.Mutex
+}
+
+func newReturnTracker(ch *amqp.Channel) *returnTracker {
+	t := &returnTracker{
+		// unbuffered, the channel waits for us to take the return before moving on to the ack
+		returns:  ch.NotifyReturn(make(chan amqp.Return)),
+		barriers: make(chan chan struct{}),
+		done:     make(chan struct{}),
+		pending:  make(map[string]*amqp.Return),
+	}
+
+	go t.run()
+
+	return t
+}
+
+func (t *returnTracker) run() {
+	defer close(t.done)
+
+	for {
+		select {
+		case ret, ok := <-t.returns:
+			if !ok {
+				return
+			}
+
+			t.mu.Lock()
+			if _, waiting := t.pending[ret.MessageId]; waiting {
+				t.pending[ret.MessageId] = &ret
+			} else {
+				log.Printf("Message %s to %s returned after its publish gave up: %s", ret.MessageId, ret.RoutingKey, ret.ReplyText)
+			}
+			t.mu.Unlock()
+
+		case barrier := <-t.barriers:
+			close(barrier)
+		}
+	}
+}
+
+// expect starts tracking a message, call it before publishing.
+func (t *returnTracker) expect(messageID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending[messageID] = nil
+}
+
+// returned stops tracking the message and returns it if the broker sent it back.
+// It is only reliable once the publish was confirmed.
+func (t *returnTracker) returned(messageID string) *amqp.Return {
+	barrier := make(chan struct{})
+
+	select {
+	case t.barriers <- barrier:
+		<-barrier
+	case <-t.done:
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	ret := t.pending[messageID]
+	delete(t.pending, messageID)
+
+	return ret
+}
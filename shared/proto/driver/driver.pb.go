@@ -126,6 +126,7 @@ type Driver struct {
 	Geohash        string                 `protobuf:"bytes,5,opt,name=geohash,proto3" json:"geohash,omitempty"`
 	PackageSlug    string                 `protobuf:"bytes,6,opt,name=packageSlug,proto3" json:"packageSlug,omitempty"`
 	Location       *Location              `protobuf:"bytes,7,opt,name=location,proto3" json:"location,omitempty"`
+	Status         string                 `protobuf:"bytes,8,opt,name=status,proto3" json:"status,omitempty"` // offline, available, offered, en_route_to_pickup, on_trip or on_break
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -209,6 +210,13 @@ func (x *Driver) GetLocation() *Location {
 	return nil
 }
 
+func (x *Driver) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
 type Location struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Latitude      float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
@@ -261,6 +269,390 @@ func (x *Location) GetLongitude() float64 {
 	return 0
 }
 
+type LocationUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DriverID      string                 `protobuf:"bytes,1,opt,name=driverID,proto3" json:"driverID,omitempty"`
+	Location      *Location              `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LocationUpdate) Reset() {
+	*x = LocationUpdate{}
+	mi := &file_driver_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LocationUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LocationUpdate) ProtoMessage() {}
+
+func (x *LocationUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_driver_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LocationUpdate.ProtoReflect.Descriptor instead.
+func (*LocationUpdate) Descriptor() ([]byte, []int) {
+	return file_driver_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *LocationUpdate) GetDriverID() string {
+	if x != nil {
+		return x.DriverID
+	}
+	return ""
+}
+
+func (x *LocationUpdate) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+type StreamLocationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Updates       int64                  `protobuf:"varint,1,opt,name=updates,proto3" json:"updates,omitempty"` // how many updates were applied
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamLocationResponse) Reset() {
+	*x = StreamLocationResponse{}
+	mi := &file_driver_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamLocationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamLocationResponse) ProtoMessage() {}
+
+func (x *StreamLocationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_driver_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamLocationResponse.ProtoReflect.Descriptor instead.
+func (*StreamLocationResponse) Descriptor() ([]byte, []int) {
+	return file_driver_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StreamLocationResponse) GetUpdates() int64 {
+	if x != nil {
+		return x.Updates
+	}
+	return 0
+}
+
+type WatchNearbyDriversRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Location      *Location              `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	RadiusKm      float64                `protobuf:"fixed64,2,opt,name=radiusKm,proto3" json:"radiusKm,omitempty"`     // 0 for the default search radius
+	PackageSlug   string                 `protobuf:"bytes,3,opt,name=packageSlug,proto3" json:"packageSlug,omitempty"` // empty for every package
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchNearbyDriversRequest) Reset() {
+	*x = WatchNearbyDriversRequest{}
+	mi := &file_driver_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchNearbyDriversRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchNearbyDriversRequest) ProtoMessage() {}
+
+func (x *WatchNearbyDriversRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_driver_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchNearbyDriversRequest.ProtoReflect.Descriptor instead.
+func (*WatchNearbyDriversRequest) Descriptor() ([]byte, []int) {
+	return file_driver_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WatchNearbyDriversRequest) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *WatchNearbyDriversRequest) GetRadiusKm() float64 {
+	if x != nil {
+		return x.RadiusKm
+	}
+	return 0
+}
+
+func (x *WatchNearbyDriversRequest) GetPackageSlug() string {
+	if x != nil {
+		return x.PackageSlug
+	}
+	return ""
+}
+
+type NearbyDrivers struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Drivers       []*Driver              `protobuf:"bytes,1,rep,name=drivers,proto3" json:"drivers,omitempty"` // closest first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NearbyDrivers) Reset() {
+	*x = NearbyDrivers{}
+	mi := &file_driver_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NearbyDrivers) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NearbyDrivers) ProtoMessage() {}
+
+func (x *NearbyDrivers) ProtoReflect() protoreflect.Message {
+	mi := &file_driver_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NearbyDrivers.ProtoReflect.Descriptor instead.
+func (*NearbyDrivers) Descriptor() ([]byte, []int) {
+	return file_driver_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *NearbyDrivers) GetDrivers() []*Driver {
+	if x != nil {
+		return x.Drivers
+	}
+	return nil
+}
+
+type SetDriverStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DriverID      string                 `protobuf:"bytes,1,opt,name=driverID,proto3" json:"driverID,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // available, on_break or offline
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDriverStatusRequest) Reset() {
+	*x = SetDriverStatusRequest{}
+	mi := &file_driver_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDriverStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDriverStatusRequest) ProtoMessage() {}
+
+func (x *SetDriverStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_driver_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDriverStatusRequest.ProtoReflect.Descriptor instead.
+func (*SetDriverStatusRequest) Descriptor() ([]byte, []int) {
+	return file_driver_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SetDriverStatusRequest) GetDriverID() string {
+	if x != nil {
+		return x.DriverID
+	}
+	return ""
+}
+
+func (x *SetDriverStatusRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type SetDriverStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Driver        *Driver                `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDriverStatusResponse) Reset() {
+	*x = SetDriverStatusResponse{}
+	mi := &file_driver_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDriverStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDriverStatusResponse) ProtoMessage() {}
+
+func (x *SetDriverStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_driver_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDriverStatusResponse.ProtoReflect.Descriptor instead.
+func (*SetDriverStatusResponse) Descriptor() ([]byte, []int) {
+	return file_driver_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SetDriverStatusResponse) GetDriver() *Driver {
+	if x != nil {
+		return x.Driver
+	}
+	return nil
+}
+
+type HeartbeatRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DriverID      string                 `protobuf:"bytes,1,opt,name=driverID,proto3" json:"driverID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_driver_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_driver_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_driver_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *HeartbeatRequest) GetDriverID() string {
+	if x != nil {
+		return x.DriverID
+	}
+	return ""
+}
+
+type HeartbeatResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Driver        *Driver                `protobuf:"bytes,1,opt,name=driver,proto3" json:"driver,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_driver_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_driver_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_driver_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *HeartbeatResponse) GetDriver() *Driver {
+	if x != nil {
+		return x.Driver
+	}
+	return nil
+}
+
 var File_driver_proto protoreflect.FileDescriptor
 
 const file_driver_proto_rawDesc = "" +
@@ -270,7 +662,7 @@ const file_driver_proto_rawDesc = "" +
 	"\bdriverID\x18\x01 \x01(\tR\bdriverID\x12 \n" +
 	"\vpackageSlug\x18\x02 \x01(\tR\vpackageSlug\"@\n" +
 	"\x16RegisterDriverResponse\x12&\n" +
-	"\x06driver\x18\x01 \x01(\v2\x0e.driver.DriverR\x06driver\"\xda\x01\n" +
+	"\x06driver\x18\x01 \x01(\v2\x0e.driver.DriverR\x06driver\"\xf2\x01\n" +
 	"\x06Driver\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12&\n" +
@@ -278,13 +670,38 @@ const file_driver_proto_rawDesc = "" +
 	"\bcarPlate\x18\x04 \x01(\tR\bcarPlate\x12\x18\n" +
 	"\ageohash\x18\x05 \x01(\tR\ageohash\x12 \n" +
 	"\vpackageSlug\x18\x06 \x01(\tR\vpackageSlug\x12,\n" +
-	"\blocation\x18\a \x01(\v2\x10.driver.LocationR\blocation\"D\n" +
+	"\blocation\x18\a \x01(\v2\x10.driver.LocationR\blocation\x12\x16\n" +
+	"\x06status\x18\b \x01(\tR\x06status\"D\n" +
 	"\bLocation\x12\x1a\n" +
 	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
-	"\tlongitude\x18\x02 \x01(\x01R\tlongitude2\xb3\x01\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\"Z\n" +
+	"\x0eLocationUpdate\x12\x1a\n" +
+	"\bdriverID\x18\x01 \x01(\tR\bdriverID\x12,\n" +
+	"\blocation\x18\x02 \x01(\v2\x10.driver.LocationR\blocation\"2\n" +
+	"\x16StreamLocationResponse\x12\x18\n" +
+	"\aupdates\x18\x01 \x01(\x03R\aupdates\"\x87\x01\n" +
+	"\x19WatchNearbyDriversRequest\x12,\n" +
+	"\blocation\x18\x01 \x01(\v2\x10.driver.LocationR\blocation\x12\x1a\n" +
+	"\bradiusKm\x18\x02 \x01(\x01R\bradiusKm\x12 \n" +
+	"\vpackageSlug\x18\x03 \x01(\tR\vpackageSlug\"9\n" +
+	"\rNearbyDrivers\x12(\n" +
+	"\adrivers\x18\x01 \x03(\v2\x0e.driver.DriverR\adrivers\"L\n" +
+	"\x16SetDriverStatusRequest\x12\x1a\n" +
+	"\bdriverID\x18\x01 \x01(\tR\bdriverID\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"A\n" +
+	"\x17SetDriverStatusResponse\x12&\n" +
+	"\x06driver\x18\x01 \x01(\v2\x0e.driver.DriverR\x06driver\".\n" +
+	"\x10HeartbeatRequest\x12\x1a\n" +
+	"\bdriverID\x18\x01 \x01(\tR\bdriverID\";\n" +
+	"\x11HeartbeatResponse\x12&\n" +
+	"\x06driver\x18\x01 \x01(\v2\x0e.driver.DriverR\x06driver2\xe7\x03\n" +
 	"\rDriverService\x12O\n" +
 	"\x0eRegisterDriver\x12\x1d.driver.RegisterDriverRequest\x1a\x1e.driver.RegisterDriverResponse\x12Q\n" +
-	"\x10UnregisterDriver\x12\x1d.driver.RegisterDriverRequest\x1a\x1e.driver.RegisterDriverResponseB\x1cZ\x1ashared/proto/driver;driverb\x06proto3"
+	"\x10UnregisterDriver\x12\x1d.driver.RegisterDriverRequest\x1a\x1e.driver.RegisterDriverResponse\x12J\n" +
+	"\x0eStreamLocation\x12\x16.driver.LocationUpdate\x1a\x1e.driver.StreamLocationResponse(\x01\x12P\n" +
+	"\x12WatchNearbyDrivers\x12!.driver.WatchNearbyDriversRequest\x1a\x15.driver.NearbyDrivers0\x01\x12R\n" +
+	"\x0fSetDriverStatus\x12\x1e.driver.SetDriverStatusRequest\x1a\x1f.driver.SetDriverStatusResponse\x12@\n" +
+	"\tHeartbeat\x12\x18.driver.HeartbeatRequest\x1a\x19.driver.HeartbeatResponseB\x1cZ\x1ashared/proto/driver;driverb\x06proto3"
 
 var (
 	file_driver_proto_rawDescOnce sync.Once
@@ -298,25 +715,46 @@ func file_driver_proto_rawDescGZIP() []byte {
 	return file_driver_proto_rawDescData
 }
 
-var file_driver_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_driver_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_driver_proto_goTypes = []any{
-	(*RegisterDriverRequest)(nil),  // 0: driver.RegisterDriverRequest
-	(*RegisterDriverResponse)(nil), // 1: driver.RegisterDriverResponse
-	(*Driver)(nil),                 // 2: driver.Driver
-	(*Location)(nil),               // 3: driver.Location
+	(*RegisterDriverRequest)(nil),     // 0: driver.RegisterDriverRequest
+	(*RegisterDriverResponse)(nil),    // 1: driver.RegisterDriverResponse
+	(*Driver)(nil),                    // 2: driver.Driver
+	(*Location)(nil),                  // 3: driver.Location
+	(*LocationUpdate)(nil),            // 4: driver.LocationUpdate
+	(*StreamLocationResponse)(nil),    // 5: driver.StreamLocationResponse
+	(*WatchNearbyDriversRequest)(nil), // 6: driver.WatchNearbyDriversRequest
+	(*NearbyDrivers)(nil),             // 7: driver.NearbyDrivers
+	(*SetDriverStatusRequest)(nil),    // 8: driver.SetDriverStatusRequest
+	(*SetDriverStatusResponse)(nil),   // 9: driver.SetDriverStatusResponse
+	(*HeartbeatRequest)(nil),          // 10: driver.HeartbeatRequest
+	(*HeartbeatResponse)(nil),         // 11: driver.HeartbeatResponse
 }
 var file_driver_proto_depIdxs = []int32{
-	2, // 0: driver.RegisterDriverResponse.driver:type_name -> driver.Driver
-	3, // 1: driver.Driver.location:type_name -> driver.Location
-	0, // 2: driver.DriverService.RegisterDriver:input_type -> driver.RegisterDriverRequest
-	0, // 3: driver.DriverService.UnregisterDriver:input_type -> driver.RegisterDriverRequest
-	1, // 4: driver.DriverService.RegisterDriver:output_type -> driver.RegisterDriverResponse
-	1, // 5: driver.DriverService.UnregisterDriver:output_type -> driver.RegisterDriverResponse
-	4, // [4:6] is the sub-list for method output_type
-	2, // [2:4] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	2,  // 0: driver.RegisterDriverResponse.driver:type_name -> driver.Driver
+	3,  // 1: driver.Driver.location:type_name -> driver.Location
+	3,  // 2: driver.LocationUpdate.location:type_name -> driver.Location
+	3,  // 3: driver.WatchNearbyDriversRequest.location:type_name -> driver.Location
+	2,  // 4: driver.NearbyDrivers.drivers:type_name -> driver.Driver
+	2,  // 5: driver.SetDriverStatusResponse.driver:type_name -> driver.Driver
+	2,  // 6: driver.HeartbeatResponse.driver:type_name -> driver.Driver
+	0,  // 7: driver.DriverService.RegisterDriver:input_type -> driver.RegisterDriverRequest
+	0,  // 8: driver.DriverService.UnregisterDriver:input_type -> driver.RegisterDriverRequest
+	4,  // 9: driver.DriverService.StreamLocation:input_type -> driver.LocationUpdate
+	6,  // 10: driver.DriverService.WatchNearbyDrivers:input_type -> driver.WatchNearbyDriversRequest
+	8,  // 11: driver.DriverService.SetDriverStatus:input_type -> driver.SetDriverStatusRequest
+	10, // 12: driver.DriverService.Heartbeat:input_type -> driver.HeartbeatRequest
+	1,  // 13: driver.DriverService.RegisterDriver:output_type -> driver.RegisterDriverResponse
+	1,  // 14: driver.DriverService.UnregisterDriver:output_type -> driver.RegisterDriverResponse
+	5,  // 15: driver.DriverService.StreamLocation:output_type -> driver.StreamLocationResponse
+	7,  // 16: driver.DriverService.WatchNearbyDrivers:output_type -> driver.NearbyDrivers
+	9,  // 17: driver.DriverService.SetDriverStatus:output_type -> driver.SetDriverStatusResponse
+	11, // 18: driver.DriverService.Heartbeat:output_type -> driver.HeartbeatResponse
+	13, // [13:19] is the sub-list for method output_type
+	7,  // [7:13] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_driver_proto_init() }
@@ -330,7 +768,7 @@ func file_driver_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_driver_proto_rawDesc), len(file_driver_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,8 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DriverService_RegisterDriver_FullMethodName   = "/driver.DriverService/RegisterDriver"
-	DriverService_UnregisterDriver_FullMethodName = "/driver.DriverService/UnregisterDriver"
+	DriverService_RegisterDriver_FullMethodName     = "/driver.DriverService/RegisterDriver"
+	DriverService_UnregisterDriver_FullMethodName   = "/driver.DriverService/UnregisterDriver"
+	DriverService_StreamLocation_FullMethodName     = "/driver.DriverService/StreamLocation"
+	DriverService_WatchNearbyDrivers_FullMethodName = "/driver.DriverService/WatchNearbyDrivers"
+	DriverService_SetDriverStatus_FullMethodName    = "/driver.DriverService/SetDriverStatus"
+	DriverService_Heartbeat_FullMethodName          = "/driver.DriverService/Heartbeat"
 )
 
 // DriverServiceClient is the client API for DriverService service.
@@ -29,6 +33,14 @@ const (
 type DriverServiceClient interface {
 	RegisterDriver(ctx context.Context, in *RegisterDriverRequest, opts ...grpc.CallOption) (*RegisterDriverResponse, error)
 	UnregisterDriver(ctx context.Context, in *RegisterDriverRequest, opts ...grpc.CallOption) (*RegisterDriverResponse, error)
+	// StreamLocation moves a driver, every update on the stream must be for the same driver
+	StreamLocation(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[LocationUpdate, StreamLocationResponse], error)
+	// WatchNearbyDrivers sends the drivers around a point, again every time they move
+	WatchNearbyDrivers(ctx context.Context, in *WatchNearbyDriversRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NearbyDrivers], error)
+	// SetDriverStatus lets a driver go available, on a break or offline, trip statuses follow the trip events
+	SetDriverStatus(ctx context.Context, in *SetDriverStatusRequest, opts ...grpc.CallOption) (*SetDriverStatusResponse, error)
+	// Heartbeat keeps a connected driver online, drivers not heard of for a while are marked offline
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
 }
 
 type driverServiceClient struct {
@@ -59,12 +71,72 @@ func (c *driverServiceClient) UnregisterDriver(ctx context.Context, in *Register
 	return out, nil
 }
 
+func (c *driverServiceClient) StreamLocation(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[LocationUpdate, StreamLocationResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DriverService_ServiceDesc.Streams[0], DriverService_StreamLocation_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LocationUpdate, StreamLocationResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DriverService_StreamLocationClient = grpc.ClientStreamingClient[LocationUpdate, StreamLocationResponse]
+
+func (c *driverServiceClient) WatchNearbyDrivers(ctx context.Context, in *WatchNearbyDriversRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NearbyDrivers], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DriverService_ServiceDesc.Streams[1], DriverService_WatchNearbyDrivers_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchNearbyDriversRequest, NearbyDrivers]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DriverService_WatchNearbyDriversClient = grpc.ServerStreamingClient[NearbyDrivers]
+
+func (c *driverServiceClient) SetDriverStatus(ctx context.Context, in *SetDriverStatusRequest, opts ...grpc.CallOption) (*SetDriverStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetDriverStatusResponse)
+	err := c.cc.Invoke(ctx, DriverService_SetDriverStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *driverServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, DriverService_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DriverServiceServer is the server API for DriverService service.
 // All implementations must embed UnimplementedDriverServiceServer
 // for forward compatibility.
 type DriverServiceServer interface {
 	RegisterDriver(context.Context, *RegisterDriverRequest) (*RegisterDriverResponse, error)
 	UnregisterDriver(context.Context, *RegisterDriverRequest) (*RegisterDriverResponse, error)
+	// StreamLocation moves a driver, every update on the stream must be for the same driver
+	StreamLocation(grpc.ClientStreamingServer[LocationUpdate, StreamLocationResponse]) error
+	// WatchNearbyDrivers sends the drivers around a point, again every time they move
+	WatchNearbyDrivers(*WatchNearbyDriversRequest, grpc.ServerStreamingServer[NearbyDrivers]) error
+	// SetDriverStatus lets a driver go available, on a break or offline, trip statuses follow the trip events
+	SetDriverStatus(context.Context, *SetDriverStatusRequest) (*SetDriverStatusResponse, error)
+	// Heartbeat keeps a connected driver online, drivers not heard of for a while are marked offline
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
 	mustEmbedUnimplementedDriverServiceServer()
 }
 
@@ -81,6 +153,18 @@ func (UnimplementedDriverServiceServer) RegisterDriver(context.Context, *Registe
 func (UnimplementedDriverServiceServer) UnregisterDriver(context.Context, *RegisterDriverRequest) (*RegisterDriverResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UnregisterDriver not implemented")
 }
+func (UnimplementedDriverServiceServer) StreamLocation(grpc.ClientStreamingServer[LocationUpdate, StreamLocationResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLocation not implemented")
+}
+func (UnimplementedDriverServiceServer) WatchNearbyDrivers(*WatchNearbyDriversRequest, grpc.ServerStreamingServer[NearbyDrivers]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchNearbyDrivers not implemented")
+}
+func (UnimplementedDriverServiceServer) SetDriverStatus(context.Context, *SetDriverStatusRequest) (*SetDriverStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDriverStatus not implemented")
+}
+func (UnimplementedDriverServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
 func (UnimplementedDriverServiceServer) mustEmbedUnimplementedDriverServiceServer() {}
 func (UnimplementedDriverServiceServer) testEmbeddedByValue()                       {}
 
@@ -138,6 +222,60 @@ func _DriverService_UnregisterDriver_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DriverService_StreamLocation_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DriverServiceServer).StreamLocation(&grpc.GenericServerStream[LocationUpdate, StreamLocationResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DriverService_StreamLocationServer = grpc.ClientStreamingServer[LocationUpdate, StreamLocationResponse]
+
+func _DriverService_WatchNearbyDrivers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchNearbyDriversRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DriverServiceServer).WatchNearbyDrivers(m, &grpc.GenericServerStream[WatchNearbyDriversRequest, NearbyDrivers]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DriverService_WatchNearbyDriversServer = grpc.ServerStreamingServer[NearbyDrivers]
+
+func _DriverService_SetDriverStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDriverStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServiceServer).SetDriverStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DriverService_SetDriverStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServiceServer).SetDriverStatus(ctx, req.(*SetDriverStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DriverService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DriverService_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DriverService_ServiceDesc is the grpc.ServiceDesc for DriverService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,7 +291,26 @@ var DriverService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UnregisterDriver",
 			Handler:    _DriverService_UnregisterDriver_Handler,
 		},
+		{
+			MethodName: "SetDriverStatus",
+			Handler:    _DriverService_SetDriverStatus_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _DriverService_Heartbeat_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLocation",
+			Handler:       _DriverService_StreamLocation_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchNearbyDrivers",
+			Handler:       _DriverService_WatchNearbyDrivers_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "driver.proto",
 }
@@ -26,8 +26,10 @@ type PreviewTripRequest struct {
 	UserId        string                 `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
 	StartLocation *Coordinate            `protobuf:"bytes,2,opt,name=startLocation,proto3" json:"startLocation,omitempty"`
 	EndLocation   *Coordinate            `protobuf:"bytes,3,opt,name=endLocation,proto3" json:"endLocation,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	// skipRouteCache asks the router again instead of reusing a cached route
+	SkipRouteCache bool `protobuf:"varint,4,opt,name=skipRouteCache,proto3" json:"skipRouteCache,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *PreviewTripRequest) Reset() {
@@ -81,6 +83,13 @@ func (x *PreviewTripRequest) GetEndLocation() *Coordinate {
 	return nil
 }
 
+func (x *PreviewTripRequest) GetSkipRouteCache() bool {
+	if x != nil {
+		return x.SkipRouteCache
+	}
+	return false
+}
+
 type PreviewTripResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	TripID        string                 `protobuf:"bytes,1,opt,name=tripID,proto3" json:"tripID,omitempty"`
@@ -303,6 +312,8 @@ type RideFare struct {
 	UserID            string                 `protobuf:"bytes,2,opt,name=userID,proto3" json:"userID,omitempty"`
 	PackageSlug       string                 `protobuf:"bytes,3,opt,name=packageSlug,proto3" json:"packageSlug,omitempty"`
 	TotalPriceInCents float64                `protobuf:"fixed64,4,opt,name=totalPriceInCents,proto3" json:"totalPriceInCents,omitempty"`
+	SurgeMultiplier   float64                `protobuf:"fixed64,5,opt,name=surgeMultiplier,proto3" json:"surgeMultiplier,omitempty"` // 1 when there is no surge
+	ExpiresAt         string                 `protobuf:"bytes,6,opt,name=expiresAt,proto3" json:"expiresAt,omitempty"`               // RFC 3339
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
@@ -365,6 +376,20 @@ func (x *RideFare) GetTotalPriceInCents() float64 {
 	return 0
 }
 
+func (x *RideFare) GetSurgeMultiplier() float64 {
+	if x != nil {
+		return x.SurgeMultiplier
+	}
+	return 0
+}
+
+func (x *RideFare) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
 type CreateTripRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        string                 `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
@@ -470,15 +495,21 @@ func (x *CreateTripResponse) GetTrip() *Trip {
 }
 
 type Trip struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	SelectedFare  *RideFare              `protobuf:"bytes,2,opt,name=selectedFare,proto3" json:"selectedFare,omitempty"`
-	Route         *Route                 `protobuf:"bytes,3,opt,name=route,proto3" json:"route,omitempty"`
-	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
-	UserID        string                 `protobuf:"bytes,5,opt,name=UserID,proto3" json:"UserID,omitempty"`
-	Driver        *TripDriver            `protobuf:"bytes,6,opt,name=driver,proto3" json:"driver,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	SelectedFare   *RideFare              `protobuf:"bytes,2,opt,name=selectedFare,proto3" json:"selectedFare,omitempty"`
+	Route          *Route                 `protobuf:"bytes,3,opt,name=route,proto3" json:"route,omitempty"`
+	Status         string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	UserID         string                 `protobuf:"bytes,5,opt,name=UserID,proto3" json:"UserID,omitempty"`
+	Driver         *TripDriver            `protobuf:"bytes,6,opt,name=driver,proto3" json:"driver,omitempty"`
+	Cancellation   *TripCancellation      `protobuf:"bytes,7,opt,name=cancellation,proto3" json:"cancellation,omitempty"`        // only set on cancelled trips
+	PickedUpAt     string                 `protobuf:"bytes,8,opt,name=pickedUpAt,proto3" json:"pickedUpAt,omitempty"`            // RFC 3339
+	DroppedOffAt   string                 `protobuf:"bytes,9,opt,name=droppedOffAt,proto3" json:"droppedOffAt,omitempty"`        // RFC 3339
+	ActualDistance float64                `protobuf:"fixed64,10,opt,name=actualDistance,proto3" json:"actualDistance,omitempty"` // meters, set once completed
+	FinalFare      *FinalFare             `protobuf:"bytes,11,opt,name=finalFare,proto3" json:"finalFare,omitempty"`             // set once completed
+	CreatedAt      string                 `protobuf:"bytes,12,opt,name=createdAt,proto3" json:"createdAt,omitempty"`             // RFC 3339
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Trip) Reset() {
@@ -553,31 +584,70 @@ func (x *Trip) GetDriver() *TripDriver {
 	return nil
 }
 
-// Static driver object that is used to store the driver info
-type TripDriver struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	ProfilePicture string                 `protobuf:"bytes,3,opt,name=profilePicture,proto3" json:"profilePicture,omitempty"`
-	CarPlate       string                 `protobuf:"bytes,4,opt,name=carPlate,proto3" json:"carPlate,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+func (x *Trip) GetCancellation() *TripCancellation {
+	if x != nil {
+		return x.Cancellation
+	}
+	return nil
 }
 
-func (x *TripDriver) Reset() {
-	*x = TripDriver{}
+func (x *Trip) GetPickedUpAt() string {
+	if x != nil {
+		return x.PickedUpAt
+	}
+	return ""
+}
+
+func (x *Trip) GetDroppedOffAt() string {
+	if x != nil {
+		return x.DroppedOffAt
+	}
+	return ""
+}
+
+func (x *Trip) GetActualDistance() float64 {
+	if x != nil {
+		return x.ActualDistance
+	}
+	return 0
+}
+
+func (x *Trip) GetFinalFare() *FinalFare {
+	if x != nil {
+		return x.FinalFare
+	}
+	return nil
+}
+
+func (x *Trip) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type GetTripRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TripID        string                 `protobuf:"bytes,1,opt,name=tripID,proto3" json:"tripID,omitempty"`
+	UserID        string                 `protobuf:"bytes,2,opt,name=userID,proto3" json:"userID,omitempty"` // the trip must belong to this rider or driver
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTripRequest) Reset() {
+	*x = GetTripRequest{}
 	mi := &file_trip_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TripDriver) String() string {
+func (x *GetTripRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TripDriver) ProtoMessage() {}
+func (*GetTripRequest) ProtoMessage() {}
 
-func (x *TripDriver) ProtoReflect() protoreflect.Message {
+func (x *GetTripRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_trip_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -589,94 +659,1006 @@ func (x *TripDriver) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TripDriver.ProtoReflect.Descriptor instead.
-func (*TripDriver) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetTripRequest.ProtoReflect.Descriptor instead.
+func (*GetTripRequest) Descriptor() ([]byte, []int) {
 	return file_trip_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *TripDriver) GetId() string {
+func (x *GetTripRequest) GetTripID() string {
 	if x != nil {
-		return x.Id
+		return x.TripID
 	}
 	return ""
 }
 
-func (x *TripDriver) GetName() string {
+func (x *GetTripRequest) GetUserID() string {
 	if x != nil {
-		return x.Name
+		return x.UserID
 	}
 	return ""
 }
 
-func (x *TripDriver) GetProfilePicture() string {
+type GetTripResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trip          *Trip                  `protobuf:"bytes,1,opt,name=trip,proto3" json:"trip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTripResponse) Reset() {
+	*x = GetTripResponse{}
+	mi := &file_trip_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTripResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTripResponse) ProtoMessage() {}
+
+func (x *GetTripResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[10]
 	if x != nil {
-		return x.ProfilePicture
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTripResponse.ProtoReflect.Descriptor instead.
+func (*GetTripResponse) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetTripResponse) GetTrip() *Trip {
+	if x != nil {
+		return x.Trip
+	}
+	return nil
+}
+
+type ListTripsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserID        string                 `protobuf:"bytes,1,opt,name=userID,proto3" json:"userID,omitempty"`     // rider or driver id, depending on the call
+	Statuses      []string               `protobuf:"bytes,2,rep,name=statuses,proto3" json:"statuses,omitempty"` // empty for every status
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor        string                 `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"` // nextCursor of the previous page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTripsRequest) Reset() {
+	*x = ListTripsRequest{}
+	mi := &file_trip_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTripsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTripsRequest) ProtoMessage() {}
+
+func (x *ListTripsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTripsRequest.ProtoReflect.Descriptor instead.
+func (*ListTripsRequest) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListTripsRequest) GetUserID() string {
+	if x != nil {
+		return x.UserID
 	}
 	return ""
 }
 
-func (x *TripDriver) GetCarPlate() string {
+func (x *ListTripsRequest) GetStatuses() []string {
 	if x != nil {
-		return x.CarPlate
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListTripsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListTripsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
 	}
 	return ""
 }
 
-var File_trip_proto protoreflect.FileDescriptor
+type ListTripsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trips         []*Trip                `protobuf:"bytes,1,rep,name=trips,proto3" json:"trips,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=nextCursor,proto3" json:"nextCursor,omitempty"` // empty on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_trip_proto_rawDesc = "" +
-	"\n" +
-	"\n" +
-	"trip.proto\x12\x04trip\"\x98\x01\n" +
-	"\x12PreviewTripRequest\x12\x16\n" +
-	"\x06userId\x18\x01 \x01(\tR\x06userId\x126\n" +
-	"\rstartLocation\x18\x02 \x01(\v2\x10.trip.CoordinateR\rstartLocation\x122\n" +
-	"\vendLocation\x18\x03 \x01(\v2\x10.trip.CoordinateR\vendLocation\"~\n" +
-	"\x13PreviewTripResponse\x12\x16\n" +
-	"\x06tripID\x18\x01 \x01(\tR\x06tripID\x12!\n" +
-	"\x05route\x18\x02 \x01(\v2\v.trip.RouteR\x05route\x12,\n" +
-	"\trideFares\x18\x03 \x03(\v2\x0e.trip.RideFareR\trideFares\"F\n" +
-	"\n" +
-	"Coordinate\x12\x1a\n" +
-	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
-	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\">\n" +
-	"\bGeometry\x122\n" +
-	"\vcoordinates\x18\x01 \x03(\v2\x10.trip.CoordinateR\vcoordinates\"k\n" +
-	"\x05Route\x12*\n" +
-	"\bgeometry\x18\x01 \x03(\v2\x0e.trip.GeometryR\bgeometry\x12\x1a\n" +
-	"\bdistance\x18\x02 \x01(\x01R\bdistance\x12\x1a\n" +
-	"\bduration\x18\x03 \x01(\x01R\bduration\"\x82\x01\n" +
-	"\bRideFare\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
-	"\x06userID\x18\x02 \x01(\tR\x06userID\x12 \n" +
-	"\vpackageSlug\x18\x03 \x01(\tR\vpackageSlug\x12,\n" +
-	"\x11totalPriceInCents\x18\x04 \x01(\x01R\x11totalPriceInCents\"K\n" +
-	"\x11CreateTripRequest\x12\x16\n" +
-	"\x06userId\x18\x01 \x01(\tR\x06userId\x12\x1e\n" +
-	"\n" +
-	"rideFareId\x18\x02 \x01(\tR\n" +
-	"rideFareId\"L\n" +
-	"\x12CreateTripResponse\x12\x16\n" +
-	"\x06tripID\x18\x01 \x01(\tR\x06tripID\x12\x1e\n" +
-	"\x04trip\x18\x02 \x01(\v2\n" +
-	".trip.TripR\x04trip\"\xc7\x01\n" +
-	"\x04Trip\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x122\n" +
-	"\fselectedFare\x18\x02 \x01(\v2\x0e.trip.RideFareR\fselectedFare\x12!\n" +
-	"\x05route\x18\x03 \x01(\v2\v.trip.RouteR\x05route\x12\x16\n" +
-	"\x06status\x18\x04 \x01(\tR\x06status\x12\x16\n" +
-	"\x06UserID\x18\x05 \x01(\tR\x06UserID\x12(\n" +
-	"\x06driver\x18\x06 \x01(\v2\x10.trip.TripDriverR\x06driver\"t\n" +
-	"\n" +
-	"TripDriver\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12&\n" +
-	"\x0eprofilePicture\x18\x03 \x01(\tR\x0eprofilePicture\x12\x1a\n" +
-	"\bcarPlate\x18\x04 \x01(\tR\bcarPlate2\x92\x01\n" +
-	"\vTripService\x12B\n" +
-	"\vPreviewTrip\x12\x18.trip.PreviewTripRequest\x1a\x19.trip.PreviewTripResponse\x12?\n" +
+func (x *ListTripsResponse) Reset() {
+	*x = ListTripsResponse{}
+	mi := &file_trip_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTripsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTripsResponse) ProtoMessage() {}
+
+func (x *ListTripsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTripsResponse.ProtoReflect.Descriptor instead.
+func (*ListTripsResponse) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListTripsResponse) GetTrips() []*Trip {
+	if x != nil {
+		return x.Trips
+	}
+	return nil
+}
+
+func (x *ListTripsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type SetOffForPickupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TripID        string                 `protobuf:"bytes,1,opt,name=tripID,proto3" json:"tripID,omitempty"`
+	DriverID      string                 `protobuf:"bytes,2,opt,name=driverID,proto3" json:"driverID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetOffForPickupRequest) Reset() {
+	*x = SetOffForPickupRequest{}
+	mi := &file_trip_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetOffForPickupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOffForPickupRequest) ProtoMessage() {}
+
+func (x *SetOffForPickupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOffForPickupRequest.ProtoReflect.Descriptor instead.
+func (*SetOffForPickupRequest) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SetOffForPickupRequest) GetTripID() string {
+	if x != nil {
+		return x.TripID
+	}
+	return ""
+}
+
+func (x *SetOffForPickupRequest) GetDriverID() string {
+	if x != nil {
+		return x.DriverID
+	}
+	return ""
+}
+
+type SetOffForPickupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trip          *Trip                  `protobuf:"bytes,1,opt,name=trip,proto3" json:"trip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetOffForPickupResponse) Reset() {
+	*x = SetOffForPickupResponse{}
+	mi := &file_trip_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetOffForPickupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetOffForPickupResponse) ProtoMessage() {}
+
+func (x *SetOffForPickupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetOffForPickupResponse.ProtoReflect.Descriptor instead.
+func (*SetOffForPickupResponse) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SetOffForPickupResponse) GetTrip() *Trip {
+	if x != nil {
+		return x.Trip
+	}
+	return nil
+}
+
+type StartTripRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TripID        string                 `protobuf:"bytes,1,opt,name=tripID,proto3" json:"tripID,omitempty"`
+	DriverID      string                 `protobuf:"bytes,2,opt,name=driverID,proto3" json:"driverID,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartTripRequest) Reset() {
+	*x = StartTripRequest{}
+	mi := &file_trip_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartTripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTripRequest) ProtoMessage() {}
+
+func (x *StartTripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTripRequest.ProtoReflect.Descriptor instead.
+func (*StartTripRequest) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StartTripRequest) GetTripID() string {
+	if x != nil {
+		return x.TripID
+	}
+	return ""
+}
+
+func (x *StartTripRequest) GetDriverID() string {
+	if x != nil {
+		return x.DriverID
+	}
+	return ""
+}
+
+type StartTripResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trip          *Trip                  `protobuf:"bytes,1,opt,name=trip,proto3" json:"trip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartTripResponse) Reset() {
+	*x = StartTripResponse{}
+	mi := &file_trip_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartTripResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTripResponse) ProtoMessage() {}
+
+func (x *StartTripResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTripResponse.ProtoReflect.Descriptor instead.
+func (*StartTripResponse) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StartTripResponse) GetTrip() *Trip {
+	if x != nil {
+		return x.Trip
+	}
+	return nil
+}
+
+type CompleteTripRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TripID         string                 `protobuf:"bytes,1,opt,name=tripID,proto3" json:"tripID,omitempty"`
+	DriverID       string                 `protobuf:"bytes,2,opt,name=driverID,proto3" json:"driverID,omitempty"`
+	DistanceMeters float64                `protobuf:"fixed64,3,opt,name=distanceMeters,proto3" json:"distanceMeters,omitempty"` // actual distance driven, 0 to use the planned route
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CompleteTripRequest) Reset() {
+	*x = CompleteTripRequest{}
+	mi := &file_trip_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteTripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteTripRequest) ProtoMessage() {}
+
+func (x *CompleteTripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteTripRequest.ProtoReflect.Descriptor instead.
+func (*CompleteTripRequest) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CompleteTripRequest) GetTripID() string {
+	if x != nil {
+		return x.TripID
+	}
+	return ""
+}
+
+func (x *CompleteTripRequest) GetDriverID() string {
+	if x != nil {
+		return x.DriverID
+	}
+	return ""
+}
+
+func (x *CompleteTripRequest) GetDistanceMeters() float64 {
+	if x != nil {
+		return x.DistanceMeters
+	}
+	return 0
+}
+
+type CompleteTripResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trip          *Trip                  `protobuf:"bytes,1,opt,name=trip,proto3" json:"trip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteTripResponse) Reset() {
+	*x = CompleteTripResponse{}
+	mi := &file_trip_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteTripResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteTripResponse) ProtoMessage() {}
+
+func (x *CompleteTripResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteTripResponse.ProtoReflect.Descriptor instead.
+func (*CompleteTripResponse) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CompleteTripResponse) GetTrip() *Trip {
+	if x != nil {
+		return x.Trip
+	}
+	return nil
+}
+
+type FinalFare struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	QuotedInCents float64                `protobuf:"fixed64,1,opt,name=quotedInCents,proto3" json:"quotedInCents,omitempty"`
+	Adjustments   []*FareAdjustment      `protobuf:"bytes,2,rep,name=adjustments,proto3" json:"adjustments,omitempty"`
+	TotalInCents  float64                `protobuf:"fixed64,3,opt,name=totalInCents,proto3" json:"totalInCents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FinalFare) Reset() {
+	*x = FinalFare{}
+	mi := &file_trip_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FinalFare) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FinalFare) ProtoMessage() {}
+
+func (x *FinalFare) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FinalFare.ProtoReflect.Descriptor instead.
+func (*FinalFare) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *FinalFare) GetQuotedInCents() float64 {
+	if x != nil {
+		return x.QuotedInCents
+	}
+	return 0
+}
+
+func (x *FinalFare) GetAdjustments() []*FareAdjustment {
+	if x != nil {
+		return x.Adjustments
+	}
+	return nil
+}
+
+func (x *FinalFare) GetTotalInCents() float64 {
+	if x != nil {
+		return x.TotalInCents
+	}
+	return 0
+}
+
+type FareAdjustment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	AmountInCents float64                `protobuf:"fixed64,2,opt,name=amountInCents,proto3" json:"amountInCents,omitempty"` // negative for refunds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FareAdjustment) Reset() {
+	*x = FareAdjustment{}
+	mi := &file_trip_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FareAdjustment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FareAdjustment) ProtoMessage() {}
+
+func (x *FareAdjustment) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FareAdjustment.ProtoReflect.Descriptor instead.
+func (*FareAdjustment) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *FareAdjustment) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *FareAdjustment) GetAmountInCents() float64 {
+	if x != nil {
+		return x.AmountInCents
+	}
+	return 0
+}
+
+type CancelTripRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TripID        string                 `protobuf:"bytes,1,opt,name=tripID,proto3" json:"tripID,omitempty"`
+	Actor         string                 `protobuf:"bytes,2,opt,name=actor,proto3" json:"actor,omitempty"`     // rider, driver or system
+	ActorID       string                 `protobuf:"bytes,3,opt,name=actorID,proto3" json:"actorID,omitempty"` // rider or driver id, empty for the system
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	Note          string                 `protobuf:"bytes,5,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTripRequest) Reset() {
+	*x = CancelTripRequest{}
+	mi := &file_trip_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTripRequest) ProtoMessage() {}
+
+func (x *CancelTripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTripRequest.ProtoReflect.Descriptor instead.
+func (*CancelTripRequest) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CancelTripRequest) GetTripID() string {
+	if x != nil {
+		return x.TripID
+	}
+	return ""
+}
+
+func (x *CancelTripRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *CancelTripRequest) GetActorID() string {
+	if x != nil {
+		return x.ActorID
+	}
+	return ""
+}
+
+func (x *CancelTripRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CancelTripRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type CancelTripResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trip          *Trip                  `protobuf:"bytes,1,opt,name=trip,proto3" json:"trip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTripResponse) Reset() {
+	*x = CancelTripResponse{}
+	mi := &file_trip_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTripResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTripResponse) ProtoMessage() {}
+
+func (x *CancelTripResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTripResponse.ProtoReflect.Descriptor instead.
+func (*CancelTripResponse) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CancelTripResponse) GetTrip() *Trip {
+	if x != nil {
+		return x.Trip
+	}
+	return nil
+}
+
+type TripCancellation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Actor         string                 `protobuf:"bytes,1,opt,name=actor,proto3" json:"actor,omitempty"`
+	ActorID       string                 `protobuf:"bytes,2,opt,name=actorID,proto3" json:"actorID,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Note          string                 `protobuf:"bytes,4,opt,name=note,proto3" json:"note,omitempty"`
+	FeeInCents    float64                `protobuf:"fixed64,5,opt,name=feeInCents,proto3" json:"feeInCents,omitempty"` // charged to the rider
+	CancelledAt   string                 `protobuf:"bytes,6,opt,name=cancelledAt,proto3" json:"cancelledAt,omitempty"` // RFC 3339
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TripCancellation) Reset() {
+	*x = TripCancellation{}
+	mi := &file_trip_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripCancellation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripCancellation) ProtoMessage() {}
+
+func (x *TripCancellation) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripCancellation.ProtoReflect.Descriptor instead.
+func (*TripCancellation) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *TripCancellation) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *TripCancellation) GetActorID() string {
+	if x != nil {
+		return x.ActorID
+	}
+	return ""
+}
+
+func (x *TripCancellation) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *TripCancellation) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *TripCancellation) GetFeeInCents() float64 {
+	if x != nil {
+		return x.FeeInCents
+	}
+	return 0
+}
+
+func (x *TripCancellation) GetCancelledAt() string {
+	if x != nil {
+		return x.CancelledAt
+	}
+	return ""
+}
+
+// Static driver object that is used to store the driver info
+type TripDriver struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ProfilePicture string                 `protobuf:"bytes,3,opt,name=profilePicture,proto3" json:"profilePicture,omitempty"`
+	CarPlate       string                 `protobuf:"bytes,4,opt,name=carPlate,proto3" json:"carPlate,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TripDriver) Reset() {
+	*x = TripDriver{}
+	mi := &file_trip_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TripDriver) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TripDriver) ProtoMessage() {}
+
+func (x *TripDriver) ProtoReflect() protoreflect.Message {
+	mi := &file_trip_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TripDriver.ProtoReflect.Descriptor instead.
+func (*TripDriver) Descriptor() ([]byte, []int) {
+	return file_trip_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *TripDriver) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *TripDriver) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TripDriver) GetProfilePicture() string {
+	if x != nil {
+		return x.ProfilePicture
+	}
+	return ""
+}
+
+func (x *TripDriver) GetCarPlate() string {
+	if x != nil {
+		return x.CarPlate
+	}
+	return ""
+}
+
+var File_trip_proto protoreflect.FileDescriptor
+
+const file_trip_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"trip.proto\x12\x04trip\"\xc0\x01\n" +
+	"\x12PreviewTripRequest\x12\x16\n" +
+	"\x06userId\x18\x01 \x01(\tR\x06userId\x126\n" +
+	"\rstartLocation\x18\x02 \x01(\v2\x10.trip.CoordinateR\rstartLocation\x122\n" +
+	"\vendLocation\x18\x03 \x01(\v2\x10.trip.CoordinateR\vendLocation\x12&\n" +
+	"\x0eskipRouteCache\x18\x04 \x01(\bR\x0eskipRouteCache\"~\n" +
+	"\x13PreviewTripResponse\x12\x16\n" +
+	"\x06tripID\x18\x01 \x01(\tR\x06tripID\x12!\n" +
+	"\x05route\x18\x02 \x01(\v2\v.trip.RouteR\x05route\x12,\n" +
+	"\trideFares\x18\x03 \x03(\v2\x0e.trip.RideFareR\trideFares\"F\n" +
+	"\n" +
+	"Coordinate\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\">\n" +
+	"\bGeometry\x122\n" +
+	"\vcoordinates\x18\x01 \x03(\v2\x10.trip.CoordinateR\vcoordinates\"k\n" +
+	"\x05Route\x12*\n" +
+	"\bgeometry\x18\x01 \x03(\v2\x0e.trip.GeometryR\bgeometry\x12\x1a\n" +
+	"\bdistance\x18\x02 \x01(\x01R\bdistance\x12\x1a\n" +
+	"\bduration\x18\x03 \x01(\x01R\bduration\"\xca\x01\n" +
+	"\bRideFare\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06userID\x18\x02 \x01(\tR\x06userID\x12 \n" +
+	"\vpackageSlug\x18\x03 \x01(\tR\vpackageSlug\x12,\n" +
+	"\x11totalPriceInCents\x18\x04 \x01(\x01R\x11totalPriceInCents\x12(\n" +
+	"\x0fsurgeMultiplier\x18\x05 \x01(\x01R\x0fsurgeMultiplier\x12\x1c\n" +
+	"\texpiresAt\x18\x06 \x01(\tR\texpiresAt\"K\n" +
+	"\x11CreateTripRequest\x12\x16\n" +
+	"\x06userId\x18\x01 \x01(\tR\x06userId\x12\x1e\n" +
+	"\n" +
+	"rideFareId\x18\x02 \x01(\tR\n" +
+	"rideFareId\"L\n" +
+	"\x12CreateTripResponse\x12\x16\n" +
+	"\x06tripID\x18\x01 \x01(\tR\x06tripID\x12\x1e\n" +
+	"\x04trip\x18\x02 \x01(\v2\n" +
+	".trip.TripR\x04trip\"\xbc\x03\n" +
+	"\x04Trip\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x122\n" +
+	"\fselectedFare\x18\x02 \x01(\v2\x0e.trip.RideFareR\fselectedFare\x12!\n" +
+	"\x05route\x18\x03 \x01(\v2\v.trip.RouteR\x05route\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x16\n" +
+	"\x06UserID\x18\x05 \x01(\tR\x06UserID\x12(\n" +
+	"\x06driver\x18\x06 \x01(\v2\x10.trip.TripDriverR\x06driver\x12:\n" +
+	"\fcancellation\x18\a \x01(\v2\x16.trip.TripCancellationR\fcancellation\x12\x1e\n" +
+	"\n" +
+	"pickedUpAt\x18\b \x01(\tR\n" +
+	"pickedUpAt\x12\"\n" +
+	"\fdroppedOffAt\x18\t \x01(\tR\fdroppedOffAt\x12&\n" +
+	"\x0eactualDistance\x18\n" +
+	" \x01(\x01R\x0eactualDistance\x12-\n" +
+	"\tfinalFare\x18\v \x01(\v2\x0f.trip.FinalFareR\tfinalFare\x12\x1c\n" +
+	"\tcreatedAt\x18\f \x01(\tR\tcreatedAt\"@\n" +
+	"\x0eGetTripRequest\x12\x16\n" +
+	"\x06tripID\x18\x01 \x01(\tR\x06tripID\x12\x16\n" +
+	"\x06userID\x18\x02 \x01(\tR\x06userID\"1\n" +
+	"\x0fGetTripResponse\x12\x1e\n" +
+	"\x04trip\x18\x01 \x01(\v2\n" +
+	".trip.TripR\x04trip\"t\n" +
+	"\x10ListTripsRequest\x12\x16\n" +
+	"\x06userID\x18\x01 \x01(\tR\x06userID\x12\x1a\n" +
+	"\bstatuses\x18\x02 \x03(\tR\bstatuses\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\x04 \x01(\tR\x06cursor\"U\n" +
+	"\x11ListTripsResponse\x12 \n" +
+	"\x05trips\x18\x01 \x03(\v2\n" +
+	".trip.TripR\x05trips\x12\x1e\n" +
+	"\n" +
+	"nextCursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"L\n" +
+	"\x16SetOffForPickupRequest\x12\x16\n" +
+	"\x06tripID\x18\x01 \x01(\tR\x06tripID\x12\x1a\n" +
+	"\bdriverID\x18\x02 \x01(\tR\bdriverID\"9\n" +
+	"\x17SetOffForPickupResponse\x12\x1e\n" +
+	"\x04trip\x18\x01 \x01(\v2\n" +
+	".trip.TripR\x04trip\"F\n" +
+	"\x10StartTripRequest\x12\x16\n" +
+	"\x06tripID\x18\x01 \x01(\tR\x06tripID\x12\x1a\n" +
+	"\bdriverID\x18\x02 \x01(\tR\bdriverID\"3\n" +
+	"\x11StartTripResponse\x12\x1e\n" +
+	"\x04trip\x18\x01 \x01(\v2\n" +
+	".trip.TripR\x04trip\"q\n" +
+	"\x13CompleteTripRequest\x12\x16\n" +
+	"\x06tripID\x18\x01 \x01(\tR\x06tripID\x12\x1a\n" +
+	"\bdriverID\x18\x02 \x01(\tR\bdriverID\x12&\n" +
+	"\x0edistanceMeters\x18\x03 \x01(\x01R\x0edistanceMeters\"6\n" +
+	"\x14CompleteTripResponse\x12\x1e\n" +
+	"\x04trip\x18\x01 \x01(\v2\n" +
+	".trip.TripR\x04trip\"\x8d\x01\n" +
+	"\tFinalFare\x12$\n" +
+	"\rquotedInCents\x18\x01 \x01(\x01R\rquotedInCents\x126\n" +
+	"\vadjustments\x18\x02 \x03(\v2\x14.trip.FareAdjustmentR\vadjustments\x12\"\n" +
+	"\ftotalInCents\x18\x03 \x01(\x01R\ftotalInCents\"N\n" +
+	"\x0eFareAdjustment\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\x12$\n" +
+	"\ramountInCents\x18\x02 \x01(\x01R\ramountInCents\"\x87\x01\n" +
+	"\x11CancelTripRequest\x12\x16\n" +
+	"\x06tripID\x18\x01 \x01(\tR\x06tripID\x12\x14\n" +
+	"\x05actor\x18\x02 \x01(\tR\x05actor\x12\x18\n" +
+	"\aactorID\x18\x03 \x01(\tR\aactorID\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x12\n" +
+	"\x04note\x18\x05 \x01(\tR\x04note\"4\n" +
+	"\x12CancelTripResponse\x12\x1e\n" +
+	"\x04trip\x18\x01 \x01(\v2\n" +
+	".trip.TripR\x04trip\"\xb0\x01\n" +
+	"\x10TripCancellation\x12\x14\n" +
+	"\x05actor\x18\x01 \x01(\tR\x05actor\x12\x18\n" +
+	"\aactorID\x18\x02 \x01(\tR\aactorID\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x12\n" +
+	"\x04note\x18\x04 \x01(\tR\x04note\x12\x1e\n" +
+	"\n" +
+	"feeInCents\x18\x05 \x01(\x01R\n" +
+	"feeInCents\x12 \n" +
+	"\vcancelledAt\x18\x06 \x01(\tR\vcancelledAt\"t\n" +
+	"\n" +
+	"TripDriver\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12&\n" +
+	"\x0eprofilePicture\x18\x03 \x01(\tR\x0eprofilePicture\x12\x1a\n" +
+	"\bcarPlate\x18\x04 \x01(\tR\bcarPlate2\xe7\x04\n" +
+	"\vTripService\x12B\n" +
+	"\vPreviewTrip\x12\x18.trip.PreviewTripRequest\x1a\x19.trip.PreviewTripResponse\x12?\n" +
+	"\n" +
+	"CreateTrip\x12\x17.trip.CreateTripRequest\x1a\x18.trip.CreateTripResponse\x12?\n" +
 	"\n" +
-	"CreateTrip\x12\x17.trip.CreateTripRequest\x1a\x18.trip.CreateTripResponseB\x18Z\x16shared/proto/trip;tripb\x06proto3"
+	"CancelTrip\x12\x17.trip.CancelTripRequest\x1a\x18.trip.CancelTripResponse\x12N\n" +
+	"\x0fSetOffForPickup\x12\x1c.trip.SetOffForPickupRequest\x1a\x1d.trip.SetOffForPickupResponse\x12<\n" +
+	"\tStartTrip\x12\x16.trip.StartTripRequest\x1a\x17.trip.StartTripResponse\x12E\n" +
+	"\fCompleteTrip\x12\x19.trip.CompleteTripRequest\x1a\x1a.trip.CompleteTripResponse\x126\n" +
+	"\aGetTrip\x12\x14.trip.GetTripRequest\x1a\x15.trip.GetTripResponse\x12A\n" +
+	"\x0eListRiderTrips\x12\x16.trip.ListTripsRequest\x1a\x17.trip.ListTripsResponse\x12B\n" +
+	"\x0fListDriverTrips\x12\x16.trip.ListTripsRequest\x1a\x17.trip.ListTripsResponseB\x18Z\x16shared/proto/trip;tripb\x06proto3"
 
 var (
 	file_trip_proto_rawDescOnce sync.Once
@@ -690,18 +1672,33 @@ func file_trip_proto_rawDescGZIP() []byte {
 	return file_trip_proto_rawDescData
 }
 
-var file_trip_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_trip_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
 var file_trip_proto_goTypes = []any{
-	(*PreviewTripRequest)(nil),  // 0: trip.PreviewTripRequest
-	(*PreviewTripResponse)(nil), // 1: trip.PreviewTripResponse
-	(*Coordinate)(nil),          // 2: trip.Coordinate
-	(*Geometry)(nil),            // 3: trip.Geometry
-	(*Route)(nil),               // 4: trip.Route
-	(*RideFare)(nil),            // 5: trip.RideFare
-	(*CreateTripRequest)(nil),   // 6: trip.CreateTripRequest
-	(*CreateTripResponse)(nil),  // 7: trip.CreateTripResponse
-	(*Trip)(nil),                // 8: trip.Trip
-	(*TripDriver)(nil),          // 9: trip.TripDriver
+	(*PreviewTripRequest)(nil),      // 0: trip.PreviewTripRequest
+	(*PreviewTripResponse)(nil),     // 1: trip.PreviewTripResponse
+	(*Coordinate)(nil),              // 2: trip.Coordinate
+	(*Geometry)(nil),                // 3: trip.Geometry
+	(*Route)(nil),                   // 4: trip.Route
+	(*RideFare)(nil),                // 5: trip.RideFare
+	(*CreateTripRequest)(nil),       // 6: trip.CreateTripRequest
+	(*CreateTripResponse)(nil),      // 7: trip.CreateTripResponse
+	(*Trip)(nil),                    // 8: trip.Trip
+	(*GetTripRequest)(nil),          // 9: trip.GetTripRequest
+	(*GetTripResponse)(nil),         // 10: trip.GetTripResponse
+	(*ListTripsRequest)(nil),        // 11: trip.ListTripsRequest
+	(*ListTripsResponse)(nil),       // 12: trip.ListTripsResponse
+	(*SetOffForPickupRequest)(nil),  // 13: trip.SetOffForPickupRequest
+	(*SetOffForPickupResponse)(nil), // 14: trip.SetOffForPickupResponse
+	(*StartTripRequest)(nil),        // 15: trip.StartTripRequest
+	(*StartTripResponse)(nil),       // 16: trip.StartTripResponse
+	(*CompleteTripRequest)(nil),     // 17: trip.CompleteTripRequest
+	(*CompleteTripResponse)(nil),    // 18: trip.CompleteTripResponse
+	(*FinalFare)(nil),               // 19: trip.FinalFare
+	(*FareAdjustment)(nil),          // 20: trip.FareAdjustment
+	(*CancelTripRequest)(nil),       // 21: trip.CancelTripRequest
+	(*CancelTripResponse)(nil),      // 22: trip.CancelTripResponse
+	(*TripCancellation)(nil),        // 23: trip.TripCancellation
+	(*TripDriver)(nil),              // 24: trip.TripDriver
 }
 var file_trip_proto_depIdxs = []int32{
 	2,  // 0: trip.PreviewTripRequest.startLocation:type_name -> trip.Coordinate
@@ -713,16 +1710,39 @@ var file_trip_proto_depIdxs = []int32{
 	8,  // 6: trip.CreateTripResponse.trip:type_name -> trip.Trip
 	5,  // 7: trip.Trip.selectedFare:type_name -> trip.RideFare
 	4,  // 8: trip.Trip.route:type_name -> trip.Route
-	9,  // 9: trip.Trip.driver:type_name -> trip.TripDriver
-	0,  // 10: trip.TripService.PreviewTrip:input_type -> trip.PreviewTripRequest
-	6,  // 11: trip.TripService.CreateTrip:input_type -> trip.CreateTripRequest
-	1,  // 12: trip.TripService.PreviewTrip:output_type -> trip.PreviewTripResponse
-	7,  // 13: trip.TripService.CreateTrip:output_type -> trip.CreateTripResponse
-	12, // [12:14] is the sub-list for method output_type
-	10, // [10:12] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	24, // 9: trip.Trip.driver:type_name -> trip.TripDriver
+	23, // 10: trip.Trip.cancellation:type_name -> trip.TripCancellation
+	19, // 11: trip.Trip.finalFare:type_name -> trip.FinalFare
+	8,  // 12: trip.GetTripResponse.trip:type_name -> trip.Trip
+	8,  // 13: trip.ListTripsResponse.trips:type_name -> trip.Trip
+	8,  // 14: trip.SetOffForPickupResponse.trip:type_name -> trip.Trip
+	8,  // 15: trip.StartTripResponse.trip:type_name -> trip.Trip
+	8,  // 16: trip.CompleteTripResponse.trip:type_name -> trip.Trip
+	20, // 17: trip.FinalFare.adjustments:type_name -> trip.FareAdjustment
+	8,  // 18: trip.CancelTripResponse.trip:type_name -> trip.Trip
+	0,  // 19: trip.TripService.PreviewTrip:input_type -> trip.PreviewTripRequest
+	6,  // 20: trip.TripService.CreateTrip:input_type -> trip.CreateTripRequest
+	21, // 21: trip.TripService.CancelTrip:input_type -> trip.CancelTripRequest
+	13, // 22: trip.TripService.SetOffForPickup:input_type -> trip.SetOffForPickupRequest
+	15, // 23: trip.TripService.StartTrip:input_type -> trip.StartTripRequest
+	17, // 24: trip.TripService.CompleteTrip:input_type -> trip.CompleteTripRequest
+	9,  // 25: trip.TripService.GetTrip:input_type -> trip.GetTripRequest
+	11, // 26: trip.TripService.ListRiderTrips:input_type -> trip.ListTripsRequest
+	11, // 27: trip.TripService.ListDriverTrips:input_type -> trip.ListTripsRequest
+	1,  // 28: trip.TripService.PreviewTrip:output_type -> trip.PreviewTripResponse
+	7,  // 29: trip.TripService.CreateTrip:output_type -> trip.CreateTripResponse
+	22, // 30: trip.TripService.CancelTrip:output_type -> trip.CancelTripResponse
+	14, // 31: trip.TripService.SetOffForPickup:output_type -> trip.SetOffForPickupResponse
+	16, // 32: trip.TripService.StartTrip:output_type -> trip.StartTripResponse
+	18, // 33: trip.TripService.CompleteTrip:output_type -> trip.CompleteTripResponse
+	10, // 34: trip.TripService.GetTrip:output_type -> trip.GetTripResponse
+	12, // 35: trip.TripService.ListRiderTrips:output_type -> trip.ListTripsResponse
+	12, // 36: trip.TripService.ListDriverTrips:output_type -> trip.ListTripsResponse
+	28, // [28:37] is the sub-list for method output_type
+	19, // [19:28] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_trip_proto_init() }
@@ -736,7 +1756,7 @@ func file_trip_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_trip_proto_rawDesc), len(file_trip_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
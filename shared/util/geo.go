@@ -0,0 +1,20 @@
+package util
+
+import "math"
+
+const earthRadiusKm = 6371.0
+
+// HaversineKm returns the great-circle distance in kilometers between two points.
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
+
+func toRadians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -62,6 +62,11 @@ export function DriverList({
                 </div>
                 <div className="text-right">
                   <p className="font-semibold">{price}</p>
+                  {fare.surgeMultiplier && fare.surgeMultiplier > 1 && (
+                    <p className="text-xs text-orange-600">
+                      {fare.surgeMultiplier.toFixed(1)}x surge
+                    </p>
+                  )}
                 </div>
               </div>
             );
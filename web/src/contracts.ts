@@ -5,6 +5,9 @@ import { Coordinate, Driver, Route, RouteFare, Trip } from "./types";
 export enum BackendEndpoints {
   PREVIEW_TRIP = "/trip/preview",
   START_TRIP = "/trip/start",
+  CANCEL_TRIP = "/trip/cancel",
+  GET_TRIP = "/trip", // + /{id}
+  LIST_TRIPS = "/trips",
   WS_DRIVERS = "/drivers",
   WS_RIDERS = "/riders",
 }
@@ -12,6 +15,7 @@ export enum BackendEndpoints {
 export enum TripEvents {
   NoDriversFound = "trip.event.no_drivers_found",
   DriverAssigned = "trip.event.driver_assigned",
+  Started = "trip.event.started",
   Completed = "trip.event.completed",
   Cancelled = "trip.event.cancelled",
   Created = "trip.event.created",
@@ -19,7 +23,9 @@ export enum TripEvents {
   DriverTripRequest = "driver.cmd.trip_request",
   DriverTripAccept = "driver.cmd.trip_accept",
   DriverTripDecline = "driver.cmd.trip_decline",
+  DriverTripCancelled = "driver.cmd.trip_cancelled",
   DriverRegister = "driver.cmd.register",
+  DriverStatus = "driver.cmd.status",
   PaymentSessionCreated = "payment.event.session_created",
 }
 
@@ -34,7 +40,7 @@ export type ServerWsMessage =
   | NoDriversFoundRequest;
 
 // Messages sent from the client to the server via the websocket
-export type ClientWsMessage = DriverResponseToTripResponse
+export type ClientWsMessage = DriverResponseToTripResponse | DriverStatusChange
 
 interface TripCreatedRequest {
   type: TripEvents.Created;
@@ -85,6 +91,14 @@ interface DriverResponseToTripResponse {
   };
 }
 
+// drivers can only pick these, the others follow their trip
+interface DriverStatusChange {
+  type: TripEvents.DriverStatus;
+  data: {
+    status: "available" | "on_break" | "offline";
+  };
+}
+
 export interface HTTPTripPreviewResponse {
   route: Route;
   rideFares: RouteFare[];
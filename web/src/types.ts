@@ -38,6 +38,7 @@ export interface RouteFare {
     packageSlug: CarPackageSlug,
     basePrice: number,
     totalPriceInCents?: number,
+    surgeMultiplier?: number,
     expiresAt: Date,
     route: Route,
 }
@@ -63,4 +64,7 @@ export interface Driver {
     name: string;
     profilePicture: string;
     carPlate: string;
+    status?: DriverStatus;
 }
+
+export type DriverStatus = "offline" | "available" | "offered" | "en_route_to_pickup" | "on_trip" | "on_break";